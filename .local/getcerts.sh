@@ -8,3 +8,4 @@ mkdir -p ssl
 
 kubectl get secret dns-masquerading-operator-webhook -o jsonpath='{.data.tls\.key}' | base64 -d > ssl/tls.key
 kubectl get secret dns-masquerading-operator-webhook -o jsonpath='{.data.tls\.crt}' | base64 -d > ssl/tls.crt
+kubectl get secret dns-masquerading-operator-webhook -o jsonpath='{.data.ca\.crt}' | base64 -d > ssl/ca.crt
@@ -0,0 +1,9 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package v1alpha1
+
+// Hub marks v1alpha1 as the conversion hub (that is, all other versions are converted from and to v1alpha1)
+func (*MasqueradingRule) Hub() {}
@@ -6,12 +6,12 @@ SPDX-License-Identifier: Apache-2.0
 package v1alpha1
 
 import (
-	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:storageversion
 //+kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
 //+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 //+genclient
@@ -37,9 +37,12 @@ type MasqueradingRuleList struct {
 
 // MasqueradingRuleSpec defines the desired state of MasqueradingRule
 type MasqueradingRuleSpec struct {
-	// +kubebuilder:validation:Pattern=^(\*|[a-z0-9]|[a-z0-9][a-z0-9-]*[a-z0-9])(\.([a-z0-9]|[a-z0-9][a-z0-9-]*[a-z0-9]))*$
+	// Source DNS name (may be a wildcard name, or an internationalized name); normalized by the defaulting webhook
+	// +kubebuilder:validation:Pattern=`^(\*|[^.*\s]+)(\.[^.*\s]+)*\.?$`
 	From string `json:"from"`
-	// +kubebuilder:validation:Pattern=^([a-z0-9]|[a-z0-9][a-z0-9-]*[a-z0-9])(\.([a-z0-9]|[a-z0-9][a-z0-9-]*[a-z0-9]))*$
+	// Target DNS name (may be an internationalized name) or IP address; normalized by the defaulting webhook, which also expands short service names
+	// (such as 'svc' or 'svc.ns') to fully qualified names
+	// +kubebuilder:validation:Pattern=`^([^.*\s]+(\.[^.*\s]+)*\.?|[0-9a-fA-F:.]*:[0-9a-fA-F:.]*)$`
 	To string `json:"to"`
 }
 
@@ -50,50 +53,48 @@ type MasqueradingRuleStatus struct {
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 
 	// List of status conditions to indicate the status of a MasqueradingRule.
-	// Known condition types are `Ready`.
+	// Known condition types are `Ready`, `Reconciling`, `Stalled` and `Conflict`.
+	// +listType=map
+	// +listMapKey=type
 	// +optional
-	Conditions []MasqueradingRuleCondition `json:"conditions,omitempty"`
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 
 	// Readable form of the state.
 	// +optional
 	State MasqueradingRuleState `json:"state,omitempty"`
-}
-
-// MasqueradingRuleCondition contains condition information for a MasqueradingRule.
-type MasqueradingRuleCondition struct {
-	// Type of the condition, known values are ('Ready').
-	Type MasqueradingRuleConditionType `json:"type"`
 
-	// Status of the condition, one of ('True', 'False', 'Unknown').
-	Status corev1.ConditionStatus `json:"status"`
-
-	// LastUpdateTime is the timestamp corresponding to the last status
-	// update of this condition.
+	// Source DNS name, in ASCII (punycode) form, as rendered into the coredns configuration.
 	// +optional
-	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+	From string `json:"from,omitempty"`
 
-	// LastTransitionTime is the timestamp corresponding to the last status
-	// change of this condition.
+	// Source DNS name, in Unicode form.
 	// +optional
-	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+	FromUnicode string `json:"fromUnicode,omitempty"`
 
-	// Reason is a brief machine readable explanation for the condition's last
-	// transition.
+	// Target DNS name or IP address, in ASCII (punycode) form, as rendered into the coredns configuration.
 	// +optional
-	Reason string `json:"reason,omitempty"`
+	To string `json:"to,omitempty"`
 
-	// Message is a human readable description of the details of the last
-	// transition, complementing reason.
+	// Target DNS name or IP address, in Unicode form.
 	// +optional
-	Message string `json:"message,omitempty"`
+	ToUnicode string `json:"toUnicode,omitempty"`
 }
 
-// MasqueradingRuleConditionType represents a MasqueradingRule condition value.
-type MasqueradingRuleConditionType string
-
+// Condition types of a MasqueradingRule; Ready, Reconciling and Stalled follow the kstatus conventions
+// (see https://github.com/kubernetes-sigs/cli-utils/blob/master/pkg/kstatus/README.md).
 const (
-	// MasqueradingRuleConditionReady represents the fact that a given MasqueradingRule is ready.
-	MasqueradingRuleConditionTypeReady MasqueradingRuleConditionType = "Ready"
+	// MasqueradingRuleConditionTypeReady represents the fact that a given MasqueradingRule is ready.
+	MasqueradingRuleConditionTypeReady = "Ready"
+
+	// MasqueradingRuleConditionTypeReconciling represents the fact that a given MasqueradingRule is being reconciled.
+	MasqueradingRuleConditionTypeReconciling = "Reconciling"
+
+	// MasqueradingRuleConditionTypeStalled represents the fact that the reconciliation of a given MasqueradingRule
+	// cannot make progress without intervention (e.g. because of a conflict, or because deletion is blocked).
+	MasqueradingRuleConditionTypeStalled = "Stalled"
+
+	// MasqueradingRuleConditionTypeConflict represents the fact that a given MasqueradingRule clashes with another rule.
+	MasqueradingRuleConditionTypeConflict = "Conflict"
 )
 
 // MasqueradingRuleState represents a condition state in a readable form
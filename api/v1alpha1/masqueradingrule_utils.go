@@ -6,47 +6,53 @@ SPDX-License-Identifier: Apache-2.0
 package v1alpha1
 
 import (
-	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-// Set state (and the 'Ready' condition) of a MasqueradingRule
+// Set state (and the 'Ready', 'Reconciling' and 'Stalled' conditions) of a MasqueradingRule;
+// note: errors are considered transient (that is, the rule is still reconciling); conflicts are marked as stalled by SetConflict()
 func (masqueradingRule *MasqueradingRule) SetState(state MasqueradingRuleState, message string) {
-	conditionStatus := corev1.ConditionUnknown
+	readyStatus := metav1.ConditionFalse
+	reconcilingStatus := metav1.ConditionFalse
+	stalledStatus := metav1.ConditionFalse
 
 	switch state {
+	case MasqueradingRuleStateNew:
+		readyStatus = metav1.ConditionUnknown
+		reconcilingStatus = metav1.ConditionTrue
+	case MasqueradingRuleStateProcessing, MasqueradingRuleStateDeleting, MasqueradingRuleStateError:
+		reconcilingStatus = metav1.ConditionTrue
 	case MasqueradingRuleStateReady:
-		conditionStatus = corev1.ConditionTrue
-	case MasqueradingRuleStateError:
-		conditionStatus = corev1.ConditionFalse
+		readyStatus = metav1.ConditionTrue
+	case MasqueradingRuleStateDeletionBlocked:
+		stalledStatus = metav1.ConditionTrue
 	}
 
-	setCondition(&masqueradingRule.Status.Conditions, MasqueradingRuleConditionTypeReady, conditionStatus, string(state), message)
+	masqueradingRule.setCondition(MasqueradingRuleConditionTypeReady, readyStatus, string(state), message)
+	masqueradingRule.setCondition(MasqueradingRuleConditionTypeReconciling, reconcilingStatus, string(state), message)
+	masqueradingRule.setCondition(MasqueradingRuleConditionTypeStalled, stalledStatus, string(state), message)
 	masqueradingRule.Status.State = state
 }
 
-func getCondition(conditions []MasqueradingRuleCondition, conditionType MasqueradingRuleConditionType) *MasqueradingRuleCondition {
-	for i := 0; i < len(conditions); i++ {
-		if conditions[i].Type == conditionType {
-			return &conditions[i]
-		}
+// Set the 'Conflict' condition of a MasqueradingRule; a conflict will not resolve by retrying, so the rule is marked as stalled in that case
+func (masqueradingRule *MasqueradingRule) SetConflict(conflict bool, message string) {
+	if conflict {
+		masqueradingRule.setCondition(MasqueradingRuleConditionTypeConflict, metav1.ConditionTrue, "Conflict", message)
+		masqueradingRule.setCondition(MasqueradingRuleConditionTypeReconciling, metav1.ConditionFalse, "Conflict", message)
+		masqueradingRule.setCondition(MasqueradingRuleConditionTypeStalled, metav1.ConditionTrue, "Conflict", message)
+	} else {
+		masqueradingRule.setCondition(MasqueradingRuleConditionTypeConflict, metav1.ConditionFalse, "NoConflict", message)
 	}
-	return nil
 }
 
-func setCondition(conditions *[]MasqueradingRuleCondition, conditionType MasqueradingRuleConditionType, conditionStatus corev1.ConditionStatus, conditionReason string, conditionMessage string) {
-	now := metav1.Now()
-
-	cond := getCondition(*conditions, conditionType)
-
-	if cond == nil {
-		*conditions = append(*conditions, MasqueradingRuleCondition{Type: conditionType, LastTransitionTime: &now})
-		cond = &(*conditions)[len(*conditions)-1]
-	} else if cond.Status != conditionStatus {
-		cond.LastTransitionTime = &now
-	}
-	cond.LastUpdateTime = &now
-	cond.Status = conditionStatus
-	cond.Reason = conditionReason
-	cond.Message = conditionMessage
+func (masqueradingRule *MasqueradingRule) setCondition(conditionType string, conditionStatus metav1.ConditionStatus, conditionReason string, conditionMessage string) {
+	// note: the last transition time is only updated if the status of the condition changes
+	apimeta.SetStatusCondition(&masqueradingRule.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             conditionStatus,
+		ObservedGeneration: masqueradingRule.Generation,
+		Reason:             conditionReason,
+		Message:            conditionMessage,
+	})
 }
@@ -0,0 +1,78 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetState(t *testing.T) {
+	tests := []struct {
+		state       MasqueradingRuleState
+		ready       metav1.ConditionStatus
+		reconciling metav1.ConditionStatus
+		stalled     metav1.ConditionStatus
+	}{
+		{MasqueradingRuleStateNew, metav1.ConditionUnknown, metav1.ConditionTrue, metav1.ConditionFalse},
+		{MasqueradingRuleStateProcessing, metav1.ConditionFalse, metav1.ConditionTrue, metav1.ConditionFalse},
+		{MasqueradingRuleStateReady, metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionFalse},
+		{MasqueradingRuleStateError, metav1.ConditionFalse, metav1.ConditionTrue, metav1.ConditionFalse},
+		{MasqueradingRuleStateDeletionBlocked, metav1.ConditionFalse, metav1.ConditionFalse, metav1.ConditionTrue},
+		{MasqueradingRuleStateDeleting, metav1.ConditionFalse, metav1.ConditionTrue, metav1.ConditionFalse},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.state), func(t *testing.T) {
+			masqueradingRule := &MasqueradingRule{ObjectMeta: metav1.ObjectMeta{Generation: 3}}
+			masqueradingRule.SetState(tt.state, "test")
+			if masqueradingRule.Status.State != tt.state {
+				t.Errorf("got state %s, want %s", masqueradingRule.Status.State, tt.state)
+			}
+			for conditionType, status := range map[string]metav1.ConditionStatus{
+				MasqueradingRuleConditionTypeReady:       tt.ready,
+				MasqueradingRuleConditionTypeReconciling: tt.reconciling,
+				MasqueradingRuleConditionTypeStalled:     tt.stalled,
+			} {
+				condition := apimeta.FindStatusCondition(masqueradingRule.Status.Conditions, conditionType)
+				if condition == nil {
+					t.Fatalf("condition %s not set", conditionType)
+				}
+				if condition.Status != status || condition.Reason != string(tt.state) || condition.ObservedGeneration != 3 {
+					t.Errorf("unexpected condition %s: %+v", conditionType, condition)
+				}
+			}
+		})
+	}
+}
+
+func TestSetConflict(t *testing.T) {
+	masqueradingRule := &MasqueradingRule{}
+	masqueradingRule.SetState(MasqueradingRuleStateError, "conflict")
+	masqueradingRule.SetConflict(true, "conflict")
+	for conditionType, status := range map[string]metav1.ConditionStatus{
+		MasqueradingRuleConditionTypeConflict:    metav1.ConditionTrue,
+		MasqueradingRuleConditionTypeReconciling: metav1.ConditionFalse,
+		MasqueradingRuleConditionTypeStalled:     metav1.ConditionTrue,
+	} {
+		if !apimeta.IsStatusConditionPresentAndEqual(masqueradingRule.Status.Conditions, conditionType, status) {
+			t.Errorf("got condition %s %+v, want status %s", conditionType, apimeta.FindStatusCondition(masqueradingRule.Status.Conditions, conditionType), status)
+		}
+	}
+
+	masqueradingRule.SetState(MasqueradingRuleStateReady, "ready")
+	masqueradingRule.SetConflict(false, "")
+	for conditionType, status := range map[string]metav1.ConditionStatus{
+		MasqueradingRuleConditionTypeConflict:    metav1.ConditionFalse,
+		MasqueradingRuleConditionTypeReconciling: metav1.ConditionFalse,
+		MasqueradingRuleConditionTypeStalled:     metav1.ConditionFalse,
+	} {
+		if !apimeta.IsStatusConditionPresentAndEqual(masqueradingRule.Status.Conditions, conditionType, status) {
+			t.Errorf("got condition %s %+v, want status %s", conditionType, apimeta.FindStatusCondition(masqueradingRule.Status.Conditions, conditionType), status)
+		}
+	}
+}
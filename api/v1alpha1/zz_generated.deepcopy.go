@@ -10,6 +10,7 @@ SPDX-License-Identifier: Apache-2.0
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -40,29 +41,6 @@ func (in *MasqueradingRule) DeepCopyObject() runtime.Object {
 	return nil
 }
 
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MasqueradingRuleCondition) DeepCopyInto(out *MasqueradingRuleCondition) {
-	*out = *in
-	if in.LastUpdateTime != nil {
-		in, out := &in.LastUpdateTime, &out.LastUpdateTime
-		*out = (*in).DeepCopy()
-	}
-	if in.LastTransitionTime != nil {
-		in, out := &in.LastTransitionTime, &out.LastTransitionTime
-		*out = (*in).DeepCopy()
-	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MasqueradingRuleCondition.
-func (in *MasqueradingRuleCondition) DeepCopy() *MasqueradingRuleCondition {
-	if in == nil {
-		return nil
-	}
-	out := new(MasqueradingRuleCondition)
-	in.DeepCopyInto(out)
-	return out
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MasqueradingRuleList) DeepCopyInto(out *MasqueradingRuleList) {
 	*out = *in
@@ -115,7 +93,7 @@ func (in *MasqueradingRuleStatus) DeepCopyInto(out *MasqueradingRuleStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]MasqueradingRuleCondition, len(*in))
+		*out = make([]v1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -0,0 +1,36 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// +kubebuilder:object:generate=true
+// +groupName=dns.cs.sap.com
+
+// Package v1beta1 contains API Schema definitions for the dns v1beta1 API group
+package v1beta1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+var (
+	// GroupVersion is group version used to register these objects
+	GroupVersion = schema.GroupVersion{Group: "dns.cs.sap.com", Version: "v1beta1"}
+
+	// SchemeBuilder is used to add go types to the GroupVersionKind scheme
+	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
+
+	// AddToScheme adds the types in this group-version to the given scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+)
+
+var (
+	// Needed by kubernetes/code-generator
+	SchemeGroupVersion = GroupVersion
+)
+
+// Needed by kubernetes/code-generator
+func Resource(resource string) schema.GroupResource {
+	return GroupVersion.WithResource(resource).GroupResource()
+}
@@ -0,0 +1,114 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/sap/dns-masquerading-operator/api/v1alpha1"
+)
+
+// Annotation used to preserve fields of the v1beta1 spec which cannot be represented in v1alpha1 (the conversion hub)
+const AnnotationSpec = "dns.cs.sap.com/v1beta1-spec"
+
+// fields of the v1beta1 spec which are not part of v1alpha1
+type specExtension struct {
+	TTL     *int32            `json:"ttl,omitempty"`
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// ConvertTo converts this MasqueradingRule to the hub version (v1alpha1)
+func (src *MasqueradingRule) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1alpha1.MasqueradingRule)
+	if !ok {
+		return fmt.Errorf("unexpected type %T", dstRaw)
+	}
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	delete(dst.Annotations, AnnotationSpec)
+	if src.Spec.TTL != nil || len(src.Spec.Options) > 0 {
+		data, err := json.Marshal(&specExtension{TTL: src.Spec.TTL, Options: src.Spec.Options})
+		if err != nil {
+			return err
+		}
+		if dst.Annotations == nil {
+			dst.Annotations = make(map[string]string)
+		}
+		dst.Annotations[AnnotationSpec] = string(data)
+	}
+	if len(dst.Annotations) == 0 {
+		dst.Annotations = nil
+	}
+
+	dst.Spec.From = src.Spec.From
+	if src.Spec.MatchType == MatchTypeWildcard {
+		dst.Spec.From = "*." + src.Spec.From
+	}
+	dst.Spec.To = ""
+	if len(src.Spec.Targets) > 0 {
+		dst.Spec.To = src.Spec.Targets[0]
+	}
+
+	dst.Status.ObservedGeneration = src.Status.ObservedGeneration
+	dst.Status.Conditions = slices.Clone(src.Status.Conditions)
+	dst.Status.State = v1alpha1.MasqueradingRuleState(src.Status.State)
+	dst.Status.From = src.Status.From
+	dst.Status.FromUnicode = src.Status.FromUnicode
+	dst.Status.To = src.Status.To
+	dst.Status.ToUnicode = src.Status.ToUnicode
+
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1alpha1) to this version
+func (dst *MasqueradingRule) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1alpha1.MasqueradingRule)
+	if !ok {
+		return fmt.Errorf("unexpected type %T", srcRaw)
+	}
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Spec.TTL = nil
+	dst.Spec.Options = nil
+	if value, ok := dst.Annotations[AnnotationSpec]; ok {
+		extension := &specExtension{}
+		if err := json.Unmarshal([]byte(value), extension); err != nil {
+			return fmt.Errorf("invalid value of annotation %s: %s", AnnotationSpec, err)
+		}
+		dst.Spec.TTL = extension.TTL
+		dst.Spec.Options = extension.Options
+		delete(dst.Annotations, AnnotationSpec)
+		if len(dst.Annotations) == 0 {
+			dst.Annotations = nil
+		}
+	}
+
+	dst.Spec.From = src.Spec.From
+	dst.Spec.MatchType = MatchTypeExact
+	if from, ok := strings.CutPrefix(src.Spec.From, "*."); ok {
+		dst.Spec.From = from
+		dst.Spec.MatchType = MatchTypeWildcard
+	}
+	dst.Spec.Targets = nil
+	if src.Spec.To != "" {
+		dst.Spec.Targets = []string{src.Spec.To}
+	}
+
+	dst.Status.ObservedGeneration = src.Status.ObservedGeneration
+	dst.Status.Conditions = slices.Clone(src.Status.Conditions)
+	dst.Status.State = MasqueradingRuleState(src.Status.State)
+	dst.Status.From = src.Status.From
+	dst.Status.FromUnicode = src.Status.FromUnicode
+	dst.Status.To = src.Status.To
+	dst.Status.ToUnicode = src.Status.ToUnicode
+
+	return nil
+}
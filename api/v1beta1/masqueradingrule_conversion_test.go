@@ -0,0 +1,86 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package v1beta1
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sap/dns-masquerading-operator/api/v1alpha1"
+)
+
+func TestConversionRoundTrip(t *testing.T) {
+	ttl := int32(30)
+	tests := []struct {
+		name     string
+		rule     *MasqueradingRule
+		expected v1alpha1.MasqueradingRuleSpec
+	}{
+		{
+			name: "exact match",
+			rule: &MasqueradingRule{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Annotations: map[string]string{"foo": "bar"}},
+				Spec: MasqueradingRuleSpec{
+					From:      "www.example.io",
+					MatchType: MatchTypeExact,
+					Targets:   []string{"1.2.3.4"},
+				},
+			},
+			expected: v1alpha1.MasqueradingRuleSpec{From: "www.example.io", To: "1.2.3.4"},
+		},
+		{
+			name: "wildcard match with ttl and options",
+			rule: &MasqueradingRule{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: MasqueradingRuleSpec{
+					From:      "example.io",
+					MatchType: MatchTypeWildcard,
+					Targets:   []string{"istio-ingressgateway.istio-system.svc.cluster.local"},
+					TTL:       &ttl,
+					Options:   map[string]string{"foo": "bar"},
+				},
+				Status: MasqueradingRuleStatus{
+					ObservedGeneration: 1,
+					Conditions: []metav1.Condition{{
+						Type:               MasqueradingRuleConditionTypeReady,
+						Status:             metav1.ConditionTrue,
+						ObservedGeneration: 1,
+						LastTransitionTime: metav1.Unix(1000, 0),
+						Reason:             string(MasqueradingRuleStateReady),
+						Message:            "masquerading rule completely reconciled",
+					}},
+					State: MasqueradingRuleStateReady,
+				},
+			},
+			expected: v1alpha1.MasqueradingRuleSpec{From: "*.example.io", To: "istio-ingressgateway.istio-system.svc.cluster.local"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := &v1alpha1.MasqueradingRule{}
+			if err := tt.rule.ConvertTo(hub); err != nil {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+			if hub.Spec != tt.expected {
+				t.Errorf("got hub spec %v, want %v", hub.Spec, tt.expected)
+			}
+			_, hasAnnotation := hub.Annotations[AnnotationSpec]
+			if hasAnnotation != (tt.rule.Spec.TTL != nil || len(tt.rule.Spec.Options) > 0) {
+				t.Errorf("unexpected annotations of hub: %v", hub.Annotations)
+			}
+
+			rule := &MasqueradingRule{}
+			if err := rule.ConvertFrom(hub); err != nil {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(rule, tt.rule) {
+				t.Errorf("round trip mismatch;\ngot:  %+v\nwant: %+v", rule, tt.rule)
+			}
+		})
+	}
+}
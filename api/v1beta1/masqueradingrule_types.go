@@ -0,0 +1,153 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="From",type=string,JSONPath=`.spec.from`
+//+kubebuilder:printcolumn:name="Match",type=string,JSONPath=`.spec.matchType`
+//+kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+//+genclient
+
+// MasqueradingRule is the Schema for the masqueradingrules API
+type MasqueradingRule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec MasqueradingRuleSpec `json:"spec,omitempty"`
+	// +kubebuilder:default={"observedGeneration":-1}
+	Status MasqueradingRuleStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// MasqueradingRuleList contains a list of MasqueradingRule
+type MasqueradingRuleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MasqueradingRule `json:"items"`
+}
+
+// MasqueradingRuleSpec defines the desired state of MasqueradingRule
+type MasqueradingRuleSpec struct {
+	// Source DNS name (may be an internationalized name); wildcard names are expressed through the match type,
+	// instead of a leading asterisk; normalized by the defaulting webhook
+	// +kubebuilder:validation:Pattern=`^[^.*\s]+(\.[^.*\s]+)*\.?$`
+	From string `json:"from"`
+	// How the source is matched; Exact matches the source name only, Wildcard matches all names below the source name
+	// (but not the source name itself)
+	// +kubebuilder:default=Exact
+	// +optional
+	MatchType MatchType `json:"matchType,omitempty"`
+	// Rewrite targets (DNS names or IP addresses); currently, exactly one target is supported
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=1
+	// +kubebuilder:validation:items:Pattern=`^([^.*\s]+(\.[^.*\s]+)*\.?|[0-9a-fA-F:.]*:[0-9a-fA-F:.]*)$`
+	Targets []string `json:"targets"`
+	// TTL (in seconds) of the rewritten records; note: currently not evaluated (the coredns default is used)
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TTL *int32 `json:"ttl,omitempty"`
+	// Additional options; note: currently not evaluated
+	// +optional
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// MatchType defines how the source of a MasqueradingRule is matched
+// +kubebuilder:validation:Enum=Exact;Wildcard
+type MatchType string
+
+const (
+	// Match the source name only
+	MatchTypeExact MatchType = "Exact"
+	// Match all names below the source name
+	MatchTypeWildcard MatchType = "Wildcard"
+)
+
+// MasqueradingRuleStatus defines the observed state of MasqueradingRule
+type MasqueradingRuleStatus struct {
+	// Observed generation
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// List of status conditions to indicate the status of a MasqueradingRule.
+	// Known condition types are `Ready`, `Reconciling`, `Stalled` and `Conflict`.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Readable form of the state.
+	// +optional
+	State MasqueradingRuleState `json:"state,omitempty"`
+
+	// Source DNS name, in ASCII (punycode) form, as rendered into the coredns configuration.
+	// +optional
+	From string `json:"from,omitempty"`
+
+	// Source DNS name, in Unicode form.
+	// +optional
+	FromUnicode string `json:"fromUnicode,omitempty"`
+
+	// Target DNS name or IP address, in ASCII (punycode) form, as rendered into the coredns configuration.
+	// +optional
+	To string `json:"to,omitempty"`
+
+	// Target DNS name or IP address, in Unicode form.
+	// +optional
+	ToUnicode string `json:"toUnicode,omitempty"`
+}
+
+// Condition types of a MasqueradingRule; Ready, Reconciling and Stalled follow the kstatus conventions
+// (see https://github.com/kubernetes-sigs/cli-utils/blob/master/pkg/kstatus/README.md).
+const (
+	// MasqueradingRuleConditionTypeReady represents the fact that a given MasqueradingRule is ready.
+	MasqueradingRuleConditionTypeReady = "Ready"
+
+	// MasqueradingRuleConditionTypeReconciling represents the fact that a given MasqueradingRule is being reconciled.
+	MasqueradingRuleConditionTypeReconciling = "Reconciling"
+
+	// MasqueradingRuleConditionTypeStalled represents the fact that the reconciliation of a given MasqueradingRule
+	// cannot make progress without intervention (e.g. because of a conflict, or because deletion is blocked).
+	MasqueradingRuleConditionTypeStalled = "Stalled"
+
+	// MasqueradingRuleConditionTypeConflict represents the fact that a given MasqueradingRule clashes with another rule.
+	MasqueradingRuleConditionTypeConflict = "Conflict"
+)
+
+// MasqueradingRuleState represents a condition state in a readable form
+// +kubebuilder:validation:Enum=New;Processing;DeletionBlocked;Deleting;Ready;Error
+type MasqueradingRuleState string
+
+// These are valid condition states
+const (
+	// Represents the fact that the MasqueradingRule was first seen.
+	MasqueradingRuleStateNew MasqueradingRuleState = "New"
+
+	// Represents the fact that the MasqueradingRule is reconciling.
+	MasqueradingRuleStateProcessing MasqueradingRuleState = "Processing"
+
+	// Represents the fact that the MasqueradingRule should be deleted, but deletion is blocked.
+	MasqueradingRuleStateDeletionBlocked MasqueradingRuleState = "DeletionBlocked"
+
+	// Represents the fact that the MasqueradingRule is being deleted.
+	MasqueradingRuleStateDeleting MasqueradingRuleState = "Deleting"
+
+	// Represents the fact that the MasqueradingRule is ready.
+	MasqueradingRuleStateReady MasqueradingRuleState = "Ready"
+
+	// Represents the fact that the MasqueradingRule is not ready resp. has an error.
+	MasqueradingRuleStateError MasqueradingRuleState = "Error"
+)
+
+func init() {
+	SchemeBuilder.Register(&MasqueradingRule{}, &MasqueradingRuleList{})
+}
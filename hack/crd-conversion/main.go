@@ -0,0 +1,74 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Adds the conversion webhook configuration to generated custom resource definitions
+// (controller-gen has no marker for that).
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/yaml"
+)
+
+func main() {
+	var serviceNamespace string
+	var serviceName string
+	var servicePath string
+	var servicePort int
+
+	flag.StringVar(&serviceNamespace, "service-namespace", "default", "Namespace of the webhook service")
+	flag.StringVar(&serviceName, "service-name", "dns-masquerading-operator-webhook", "Name of the webhook service")
+	flag.StringVar(&servicePath, "service-path", "/convert", "Path of the conversion webhook")
+	flag.IntVar(&servicePort, "service-port", 443, "Port of the webhook service")
+	flag.Parse()
+
+	for _, path := range flag.Args() {
+		if err := patch(path, serviceNamespace, serviceName, servicePath, servicePort); err != nil {
+			fmt.Fprintf(os.Stderr, "error patching %s: %s\n", path, err)
+			os.Exit(1)
+		}
+	}
+}
+
+func patch(path string, serviceNamespace string, serviceName string, servicePath string, servicePort int) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var crd map[string]any
+	if err := yaml.Unmarshal(bytes.TrimPrefix(data, []byte("---\n")), &crd); err != nil {
+		return err
+	}
+	spec, ok := crd["spec"].(map[string]any)
+	if !ok {
+		return fmt.Errorf("not a custom resource definition")
+	}
+	// note: the client config is adjusted by the operator at startup if --conversion-webhook-service is set
+	spec["conversion"] = map[string]any{
+		"strategy": "Webhook",
+		"webhook": map[string]any{
+			"clientConfig": map[string]any{
+				"service": map[string]any{
+					"namespace": serviceNamespace,
+					"name":      serviceName,
+					"path":      servicePath,
+					"port":      servicePort,
+				},
+			},
+			"conversionReviewVersions": []any{"v1"},
+		},
+	}
+
+	data, err = yaml.Marshal(crd)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append([]byte("---\n"), data...), 0644)
+}
@@ -17,11 +17,13 @@ mkdir -p "$TEMPDIR"
 
 mkdir -p "$TEMPDIR"/apis/dns.cs.sap.com
 ln -s "$BASEDIR"/api/v1alpha1 "$TEMPDIR"/apis/dns.cs.sap.com/v1alpha1
+ln -s "$BASEDIR"/api/v1beta1 "$TEMPDIR"/apis/dns.cs.sap.com/v1beta1
 
 "$GOBIN"/client-gen \
   --clientset-name versioned \
   --input-base "$TEMPDIR"/apis \
   --input dns.cs.sap.com/v1alpha1 \
+  --input dns.cs.sap.com/v1beta1 \
   --go-header-file "$BASEDIR"/hack/boilerplate.go.txt \
   --output-pkg github.com/sap/dns-masquerading-operator/pkg/client/clientset \
   --output-dir "$TEMPDIR"/pkg/client/clientset \
@@ -32,7 +34,8 @@ ln -s "$BASEDIR"/api/v1alpha1 "$TEMPDIR"/apis/dns.cs.sap.com/v1alpha1
   --output-pkg github.com/sap/dns-masquerading-operator/pkg/client/listers \
   --output-dir "$TEMPDIR"/pkg/client/listers \
   --plural-exceptions MasqueradingRule:masqueradingrules \
-  github.com/sap/dns-masquerading-operator/tmp/gen/apis/dns.cs.sap.com/v1alpha1
+  github.com/sap/dns-masquerading-operator/tmp/gen/apis/dns.cs.sap.com/v1alpha1 \
+  github.com/sap/dns-masquerading-operator/tmp/gen/apis/dns.cs.sap.com/v1beta1
 
 "$GOBIN"/informer-gen \
   --versioned-clientset-package github.com/sap/dns-masquerading-operator/pkg/client/clientset/versioned \
@@ -41,10 +44,11 @@ ln -s "$BASEDIR"/api/v1alpha1 "$TEMPDIR"/apis/dns.cs.sap.com/v1alpha1
   --output-pkg github.com/sap/dns-masquerading-operator/pkg/client/informers \
   --output-dir "$TEMPDIR"/pkg/client/informers \
   --plural-exceptions MasqueradingRule:masqueradingrules \
-  github.com/sap/dns-masquerading-operator/tmp/gen/apis/dns.cs.sap.com/v1alpha1
+  github.com/sap/dns-masquerading-operator/tmp/gen/apis/dns.cs.sap.com/v1alpha1 \
+  github.com/sap/dns-masquerading-operator/tmp/gen/apis/dns.cs.sap.com/v1beta1
 
 find "$TEMPDIR"/pkg/client -name "*.go" -exec \
-  perl -pi -e "s#github\.com/sap/dns-masquerading-operator/tmp/gen/apis/dns\.cs\.sap\.com/v1alpha1#github.com/sap/dns-masquerading-operator/api/v1alpha1#g" \
+  perl -pi -e "s#github\.com/sap/dns-masquerading-operator/tmp/gen/apis/dns\.cs\.sap\.com/(v1alpha1|v1beta1)#github.com/sap/dns-masquerading-operator/api/\1#g" \
   {} +
 
 rm -rf "$BASEDIR"/pkg/client
@@ -7,17 +7,34 @@ package controllers
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
 
 	"github.com/pkg/errors"
+	"github.com/sap/go-generics/maps"
+	"github.com/sap/go-generics/slices"
 
+	istionetworkingv1beta1 "istio.io/client-go/pkg/apis/networking/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	dnsv1alpha1 "github.com/sap/dns-masquerading-operator/api/v1alpha1"
+	"github.com/sap/dns-masquerading-operator/internal/coredns"
+	"github.com/sap/dns-masquerading-operator/internal/dnsname"
 )
 
 const (
@@ -32,6 +49,14 @@ const (
 	annotationMasqueradeFrom     = "dns.cs.sap.com/masquerade-from"
 	annotationMasqueradeTo       = "dns.cs.sap.com/masquerade-to"
 	annotationMasqueradeToLegacy = "masquerading-operator.dns.sap.com/masquerade-to"
+	annotationMasqueradeToAuto   = "dns.cs.sap.com/masquerade-to-auto"
+	annotationMasqueradeMap      = "dns.cs.sap.com/masquerade-map"
+	annotationMasqueradingStatus = "dns.cs.sap.com/masquerading-status"
+)
+
+const (
+	// special value of the masquerade-to annotation, meaning that the target is taken from the load balancer status of the resource
+	masqueradeToStatus = "status"
 )
 
 const (
@@ -39,103 +64,408 @@ const (
 	finalizer  = "dns.cs.sap.com/masquerading-operator"
 )
 
-// manage dependent masquerading rules of an arbitrary resource
-func manageDependents(ctx context.Context, c client.Client, obj client.Object, hosts []string) error {
+// manage dependent masquerading rules of an arbitrary resource; the rewrite targets are determined by getTargets()
+func manageDependents(ctx context.Context, c client.Client, recorder record.EventRecorder, obj client.Object, hosts []string, defaultTo string) error {
+	targets, err := getTargets(ctx, c, recorder, obj, hosts, defaultTo)
+	if err != nil {
+		return err
+	}
+	return manageDependentsWithTargets(ctx, c, obj, targets)
+}
+
+// determine rewrite targets of an arbitrary resource; the rewrite target is taken from the masquerade-to annotation,
+// or, if that is missing, from defaultTo (which may be empty, meaning that no rules will be created); if the annotation has the value 'status',
+// the target is taken from the load balancer status of the resource (again, no rules will be created if the status is empty);
+// host-specific targets given in the masquerade-map annotation take precedence
+func getTargets(ctx context.Context, c client.Client, recorder record.EventRecorder, obj client.Object, hosts []string, defaultTo string) (map[string]string, error) {
+	targets := make(map[string]string)
+	if obj.GetDeletionTimestamp().IsZero() {
+		to, err := getTarget(ctx, c, obj, defaultTo)
+		if err != nil {
+			return nil, err
+		}
+		mappedTargets := getMappedTargets(recorder, obj, hosts)
+		for _, host := range hosts {
+			if mappedTo, ok := mappedTargets[host]; ok {
+				targets[host] = mappedTo
+			} else if to != "" {
+				targets[host] = to
+			}
+		}
+	}
+	return targets, nil
+}
+
+// determine the rewrite target of an arbitrary resource from its masquerade-to annotation (resolving the special value 'status'),
+// or, if that is missing, return defaultTo
+func getTarget(ctx context.Context, c client.Client, obj client.Object, defaultTo string) (string, error) {
+	to := obj.GetAnnotations()[annotationMasqueradeTo]
+	// TODO: the following can be removed in the future
+	if to == "" {
+		to = obj.GetAnnotations()[annotationMasqueradeToLegacy]
+	}
+	if to == "" {
+		to = defaultTo
+	}
+	if to == masqueradeToStatus {
+		return getStatusTarget(ctx, c, obj)
+	}
+	return to, nil
+}
+
+// determine host-specific rewrite targets of a resource whose spec maps hosts to targets (such as dns record resources);
+// the hosts are filtered through getHosts(), and the masquerade-to annotation (if present) overrides the targets given in the spec;
+// host-specific targets given in the masquerade-map annotation take precedence
+func getRecordTargets(ctx context.Context, c client.Client, recorder record.EventRecorder, obj client.Object, records map[string]string) (map[string]string, error) {
+	override, err := getTarget(ctx, c, obj, "")
+	if err != nil {
+		return nil, err
+	}
+	hosts := getHosts(ctx, obj, maps.Keys(records)...)
+	mappedTargets := getMappedTargets(recorder, obj, hosts)
+	targets := make(map[string]string)
+	for _, host := range hosts {
+		to := override
+		if to == "" {
+			to = records[host]
+		}
+		if mappedTo, ok := mappedTargets[host]; ok {
+			to = mappedTo
+		}
+		if to != "" {
+			targets[host] = to
+		}
+	}
+	return targets, nil
+}
+
+// determine host-specific rewrite targets from the masquerade-map annotation of a resource; the annotation is either a comma-separated
+// list of host=target pairs, or a JSON object mapping hosts to targets; syntax errors, invalid entries, and entries referring to hosts
+// other than the given ones are reported as warning events on the resource (and ignored); note: repeated reconciles report the same
+// problems again, but identical events are aggregated (and rate limited) by the event recorder
+func getMappedTargets(recorder record.EventRecorder, obj client.Object, hosts []string) map[string]string {
+	targets, problems := checkMappedTargets(obj, hosts)
+	for _, problem := range problems {
+		recorder.Eventf(obj, corev1.EventTypeWarning, "InvalidMasqueradeMap", "ignoring %s", problem)
+	}
+	return targets
+}
+
+// parse the masquerade-map annotation of a resource (see getMappedTargets); returns the valid entries, and a description of each problem found
+func checkMappedTargets(obj client.Object, hosts []string) (map[string]string, []string) {
+	value := strings.TrimSpace(obj.GetAnnotations()[annotationMasqueradeMap])
+	if value == "" {
+		return nil, nil
+	}
+
+	entries, err := parseMasqueradeMap(value)
+	if err != nil {
+		return nil, []string{fmt.Sprintf("annotation %s: %s", annotationMasqueradeMap, err)}
+	}
+
+	targets := make(map[string]string)
+	var problems []string
+	for _, host := range slices.Sort(maps.Keys(entries)) {
+		to := entries[host]
+		if err := dnsname.Validate(host, coredns.SourceNameOptions); err != nil {
+			problems = append(problems, fmt.Sprintf("entry %s=%s of annotation %s: invalid host", host, to, annotationMasqueradeMap))
+			continue
+		}
+		if net.ParseIP(to) == nil && dnsname.Validate(to, coredns.TargetNameOptions) != nil {
+			problems = append(problems, fmt.Sprintf("entry %s=%s of annotation %s: invalid target", host, to, annotationMasqueradeMap))
+			continue
+		}
+		if !slices.Contains(hosts, host) {
+			problems = append(problems, fmt.Sprintf("entry %s=%s of annotation %s: host is not served by this resource", host, to, annotationMasqueradeMap))
+			continue
+		}
+		targets[host] = to
+	}
+	return targets, problems
+}
+
+// validate the masquerading related annotations of a resource; that is, check that all hosts (the given ones, usually derived from the spec,
+// and the ones listed in the host annotations) are valid, and that the masquerade-map annotation is free of problems
+func validateAnnotations(obj client.Object, hosts ...string) error {
+	validHosts, invalidHosts := checkHosts(obj, hosts...)
+	var problems []string
+	for _, host := range invalidHosts {
+		problems = append(problems, fmt.Sprintf("invalid host %s", host))
+	}
+	_, mapProblems := checkMappedTargets(obj, validHosts)
+	problems = append(problems, mapProblems...)
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid masquerading configuration: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// parse value of the masquerade-map annotation (comma-separated list of host=target pairs, or JSON object); hosts and targets are normalized
+func parseMasqueradeMap(value string) (map[string]string, error) {
+	raw := make(map[string]string)
+	if strings.HasPrefix(value, "{") {
+		if err := json.Unmarshal([]byte(value), &raw); err != nil {
+			return nil, errors.Wrap(err, "invalid JSON")
+		}
+	} else {
+		for _, entry := range strings.Split(value, ",") {
+			if strings.TrimSpace(entry) == "" {
+				continue
+			}
+			host, to, ok := strings.Cut(entry, "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid entry %q (expected host=target)", strings.TrimSpace(entry))
+			}
+			if _, ok := raw[normalizeHost(host)]; ok {
+				return nil, fmt.Errorf("duplicate host %q", normalizeHost(host))
+			}
+			raw[normalizeHost(host)] = to
+		}
+	}
+	entries := make(map[string]string)
+	for host, to := range raw {
+		entries[normalizeHost(host)] = normalizeHost(to)
+	}
+	return entries, nil
+}
+
+// manage dependent masquerading rules of an arbitrary resource, such that there is exactly one masquerading rule for each entry
+// of targets (mapping hosts to rewrite targets); the masquerading rules are owned by the resource (and therefore garbage-collected
+// when the resource is deleted), and are maintained through server-side apply, such that changed targets are updated in place
+func manageDependentsWithTargets(ctx context.Context, c client.Client, obj client.Object, targets map[string]string) error {
 	log := ctrl.LoggerFrom(ctx)
 
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return errors.Wrap(err, "failed to determine group version kind")
+	}
+
 	masqueradingRuleList := &dnsv1alpha1.MasqueradingRuleList{}
 	if err := c.List(ctx, masqueradingRuleList, client.InNamespace(obj.GetNamespace()), client.MatchingLabels{labelControllerUid: string(obj.GetUID())}); err != nil {
 		return errors.Wrap(err, "failed to list dependent masquerading rules")
 	}
-	numDependents := len(masqueradingRuleList.Items)
 
+	masqueradingRules := make(map[string]*dnsv1alpha1.MasqueradingRule)
 	if obj.GetDeletionTimestamp().IsZero() {
-		var masqueradingRules []*dnsv1alpha1.MasqueradingRule
-		to := obj.GetAnnotations()[annotationMasqueradeTo]
-		// TODO: the following can be removed in the future
-		if to == "" {
-			to = obj.GetAnnotations()[annotationMasqueradeToLegacy]
+		for from, to := range targets {
+			masqueradingRule := buildMasqueradingRule(obj, gvk, from, to)
+			masqueradingRules[masqueradingRule.Name] = masqueradingRule
 		}
+	}
 
-		if to != "" {
-			if controllerutil.AddFinalizer(obj, finalizer) {
-				if err := c.Update(ctx, obj, client.FieldOwner(fieldOwner)); err != nil {
-					return errors.Wrap(err, "failed to add finalizer")
-				}
-			}
-			for _, from := range hosts {
-				found := false
-				for _, masqueradingRule := range masqueradingRuleList.Items {
-					if masqueradingRule.Spec.From == from && masqueradingRule.Spec.To == to {
-						masqueradingRules = append(masqueradingRules, &masqueradingRule)
-						found = true
-						break
-					}
-				}
-				if !found {
-					masqueradingRule := buildMasqueradingRule(obj.GetNamespace(), obj.GetName(), obj.GetObjectKind().GroupVersionKind(), obj.GetName(), obj.GetUID(), from, to)
-					if err := c.Create(ctx, masqueradingRule, client.FieldOwner(fieldOwner)); err != nil {
-						return errors.Wrapf(err, "failed to create masquerading rule for host %s", from)
-					}
-					numDependents++
-					log.Info("created masquerading rule %s/%s", masqueradingRule.Namespace, masqueradingRule.Name)
-					masqueradingRules = append(masqueradingRules, masqueradingRule)
-				}
-			}
+	// note: stale rules are deleted before the desired ones are applied, such that a stale rule (e.g. with the same source, but created
+	// by an earlier version of this operator) cannot block its replacement; if the resource is being deleted, dependents would be
+	// garbage-collected anyway; however, masquerading rules created by earlier versions of this operator have no owner reference,
+	// so they are deleted explicitly
+	for _, masqueradingRule := range masqueradingRuleList.Items {
+		if _, ok := masqueradingRules[masqueradingRule.Name]; ok || !masqueradingRule.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if err := c.Delete(ctx, &masqueradingRule, client.PropagationPolicy(metav1.DeletePropagationForeground)); client.IgnoreNotFound(err) != nil {
+			return errors.Wrapf(err, "failed to delete masquerading rule %s/%s", masqueradingRule.Namespace, masqueradingRule.Name)
+		}
+		log.Info("deleted masquerading rule", "namespace", masqueradingRule.Namespace, "name", masqueradingRule.Name)
+	}
+
+	var appliedMasqueradingRules []dnsv1alpha1.MasqueradingRule
+	for _, name := range slices.Sort(maps.Keys(masqueradingRules)) {
+		masqueradingRule := masqueradingRules[name]
+		if err := applyMasqueradingRule(ctx, c, masqueradingRule); err != nil {
+			return errors.Wrapf(err, "failed to apply masquerading rule for host %s", masqueradingRule.Spec.From)
 		}
+		log.V(1).Info("applied masquerading rule", "namespace", masqueradingRule.Namespace, "name", masqueradingRule.Name)
+		appliedMasqueradingRules = append(appliedMasqueradingRules, *masqueradingRule)
+	}
 
-		for _, masqueradingRule := range masqueradingRuleList.Items {
-			found := false
-			for _, mr := range masqueradingRules {
-				if mr.UID == masqueradingRule.UID {
-					found = true
-					break
-				}
+	// note: the resource itself is not held back until its masquerading rules are gone (earlier versions of this operator used a finalizer
+	// for that purpose); in particular, if deletion verification is enabled, the deletion of the resource completes right away, whereas
+	// the garbage-collected masquerading rules remain (held by their own finalizer) until the rewrite is no longer served by DNS
+
+	// TODO: the following can be removed in the future (earlier versions of this operator added a finalizer to the resource)
+	if controllerutil.RemoveFinalizer(obj, finalizer) {
+		if err := c.Update(ctx, obj, client.FieldOwner(fieldOwner)); err != nil {
+			return errors.Wrap(err, "failed to remove finalizer")
+		}
+	}
+
+	if obj.GetDeletionTimestamp().IsZero() {
+		if err := updateMasqueradingStatus(ctx, c, obj, gvk, targets, appliedMasqueradingRules); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// aggregated status of the masquerading rules of a resource (as stored in the masquerading-status annotation)
+type masqueradingStatus struct {
+	// Whether all masquerading rules of the resource are ready
+	Ready bool `json:"ready"`
+	// Status of the individual hosts
+	Hosts []masqueradingHostStatus `json:"hosts"`
+}
+
+// status of the masquerading rule of a single host
+type masqueradingHostStatus struct {
+	Host    string                            `json:"host"`
+	To      string                            `json:"to"`
+	Rule    string                            `json:"rule"`
+	State   dnsv1alpha1.MasqueradingRuleState `json:"state"`
+	Message string                            `json:"message,omitempty"`
+}
+
+// write aggregated status of the masquerading rules of a resource to its masquerading-status annotation (or remove the annotation,
+// if there are no rules); the state of the individual rules is taken from masqueradingRules, which should reflect the state of the rules
+// after they were applied (rules not contained there are considered new); note that updates of the annotation do not trigger
+// a reconcile of the resource (see masqueradingStatusPredicate())
+func updateMasqueradingStatus(ctx context.Context, c client.Client, obj client.Object, gvk schema.GroupVersionKind, targets map[string]string, masqueradingRules []dnsv1alpha1.MasqueradingRule) error {
+	value := ""
+	if len(targets) > 0 {
+		status := masqueradingStatus{Ready: true}
+		for _, from := range slices.Sort(maps.Keys(targets)) {
+			hostStatus := masqueradingHostStatus{
+				Host:  from,
+				To:    targets[from],
+				Rule:  buildMasqueradingRuleName(obj.GetName(), gvk, from),
+				State: dnsv1alpha1.MasqueradingRuleStateNew,
 			}
-			if !found {
-				if masqueradingRule.DeletionTimestamp.IsZero() {
-					if err := c.Delete(ctx, &masqueradingRule, client.PropagationPolicy(metav1.DeletePropagationForeground)); err != nil {
-						return errors.Wrapf(err, "failed to delete masquerading rule %s/%s", masqueradingRule.Namespace, masqueradingRule.Name)
+			for _, masqueradingRule := range masqueradingRules {
+				if masqueradingRule.Name != hostStatus.Rule || masqueradingRule.Spec.To != hostStatus.To || masqueradingRule.Status.State == "" {
+					continue
+				}
+				// note: status is only meaningful if it refers to the current generation
+				if masqueradingRule.Status.ObservedGeneration == masqueradingRule.Generation {
+					hostStatus.State = masqueradingRule.Status.State
+				} else {
+					hostStatus.State = dnsv1alpha1.MasqueradingRuleStateProcessing
+				}
+				if hostStatus.State != dnsv1alpha1.MasqueradingRuleStateReady {
+					for _, condition := range masqueradingRule.Status.Conditions {
+						if condition.Type == dnsv1alpha1.MasqueradingRuleConditionTypeReady {
+							hostStatus.Message = condition.Message
+						}
 					}
 				}
-				numDependents--
 			}
+			if hostStatus.State != dnsv1alpha1.MasqueradingRuleStateReady {
+				status.Ready = false
+			}
+			status.Hosts = append(status.Hosts, hostStatus)
+		}
+		data, err := json.Marshal(status)
+		if err != nil {
+			return errors.Wrap(err, "failed to serialize masquerading status")
 		}
+		value = string(data)
+	}
+
+	if obj.GetAnnotations()[annotationMasqueradingStatus] == value {
+		return nil
+	}
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	annotations := obj.GetAnnotations()
+	if value == "" {
+		delete(annotations, annotationMasqueradingStatus)
 	} else {
-		for _, masqueradingRule := range masqueradingRuleList.Items {
-			if masqueradingRule.DeletionTimestamp.IsZero() {
-				if err := c.Delete(ctx, &masqueradingRule, client.PropagationPolicy(metav1.DeletePropagationForeground)); err != nil {
-					return errors.Wrapf(err, "failed to delete masquerading rule %s/%s", masqueradingRule.Namespace, masqueradingRule.Name)
-				}
-			}
-			numDependents--
+		if annotations == nil {
+			annotations = make(map[string]string)
 		}
+		annotations[annotationMasqueradingStatus] = value
+	}
+	obj.SetAnnotations(annotations)
+	if err := c.Patch(ctx, obj, patch, client.FieldOwner(fieldOwner)); err != nil {
+		return errors.Wrap(err, "failed to update masquerading status")
 	}
+	return nil
+}
 
-	if numDependents == 0 {
-		if controllerutil.RemoveFinalizer(obj, finalizer) {
-			if err := c.Update(ctx, obj, client.FieldOwner(fieldOwner)); err != nil {
-				return errors.Wrap(err, "failed to remove finalizer")
+// check if the rewrite target of the given resource may be derived automatically, in case the masquerade-to annotation is missing;
+// this is the default, and can be disabled by setting the masquerade-to-auto annotation to 'false'
+func autoTargetEnabled(obj client.Object) bool {
+	return obj.GetAnnotations()[annotationMasqueradeToAuto] != "false"
+}
+
+// determine rewrite target from the load balancer status of an arbitrary resource; that is, the first reported IP address,
+// or, if there is none, the first reported hostname; supported are services, ingresses, gateway api gateways, and istio gateways
+// (the status of the service selecting the gateway workload is used); returns an empty string if nothing was found
+func getStatusTarget(ctx context.Context, c client.Client, obj client.Object) (string, error) {
+	var ips []string
+	var hostnames []string
+	switch obj := obj.(type) {
+	case *corev1.Service:
+		ips, hostnames = getServiceLoadBalancerStatus(obj)
+	case *networkingv1.Ingress:
+		for _, ingress := range obj.Status.LoadBalancer.Ingress {
+			ips = append(ips, ingress.IP)
+			hostnames = append(hostnames, ingress.Hostname)
+		}
+	case *gatewayv1.Gateway:
+		for _, address := range obj.Status.Addresses {
+			if address.Type == nil || *address.Type == gatewayv1.IPAddressType {
+				ips = append(ips, address.Value)
+			} else if *address.Type == gatewayv1.HostnameAddressType {
+				hostnames = append(hostnames, address.Value)
 			}
 		}
+	case *istionetworkingv1beta1.Gateway:
+		service, err := getGatewayService(ctx, c, obj)
+		if err != nil {
+			return "", err
+		}
+		if service != nil {
+			ips, hostnames = getServiceLoadBalancerStatus(service)
+		}
+	default:
+		return "", fmt.Errorf("target %s is not supported for %T", masqueradeToStatus, obj)
+	}
+	for _, ip := range ips {
+		if ip != "" {
+			return ip, nil
+		}
 	}
+	for _, hostname := range hostnames {
+		if hostname != "" {
+			return hostname, nil
+		}
+	}
+	return "", nil
+}
 
-	return nil
+// return IP addresses and hostnames reported in the load balancer status of given service
+func getServiceLoadBalancerStatus(service *corev1.Service) ([]string, []string) {
+	var ips []string
+	var hostnames []string
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
+		ips = append(ips, ingress.IP)
+		hostnames = append(hostnames, ingress.Hostname)
+	}
+	return ips, hostnames
+}
+
+// return in-cluster DNS name of given service
+func getServiceAddress(service *corev1.Service, clusterDomain string) string {
+	return fmt.Sprintf("%s.%s.svc.%s", service.Name, service.Namespace, clusterDomain)
 }
 
-// build masquerading rule resource with owner
-func buildMasqueradingRule(namespace string, namePrefix string, ownerGVK schema.GroupVersionKind, ownerName string, ownerUid types.UID, from string, to string) *dnsv1alpha1.MasqueradingRule {
+// build masquerading rule resource with owner; the name of the masquerading rule is derived deterministically from
+// the owner's name and kind, and the rule source
+func buildMasqueradingRule(owner client.Object, ownerGVK schema.GroupVersionKind, from string, to string) *dnsv1alpha1.MasqueradingRule {
 	return &dnsv1alpha1.MasqueradingRule{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: dnsv1alpha1.GroupVersion.String(),
+			Kind:       "MasqueradingRule",
+		},
 		ObjectMeta: metav1.ObjectMeta{
-			Namespace:    namespace,
-			GenerateName: namePrefix + "-",
+			Namespace: owner.GetNamespace(),
+			Name:      buildMasqueradingRuleName(owner.GetName(), ownerGVK, from),
 			Labels: map[string]string{
 				labelControllerGroup:   ownerGVK.Group,
 				labelControllerVersion: ownerGVK.Version,
 				labelControllerKind:    ownerGVK.Kind,
-				labelControllerName:    ownerName,
-				labelControllerUid:     string(ownerUid),
+				labelControllerName:    owner.GetName(),
+				labelControllerUid:     string(owner.GetUID()),
 			},
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(owner, ownerGVK)},
 		},
 		Spec: dnsv1alpha1.MasqueradingRuleSpec{
 			From: from,
@@ -143,3 +473,29 @@ func buildMasqueradingRule(namespace string, namePrefix string, ownerGVK schema.
 		},
 	}
 }
+
+// build name of a masquerading rule, consisting of the owner's name and a hash of the owner's kind and the rule source
+func buildMasqueradingRuleName(ownerName string, ownerGVK schema.GroupVersionKind, from string) string {
+	hash := sha256.Sum256([]byte(ownerGVK.GroupKind().String() + "/" + from))
+	suffix := hex.EncodeToString(hash[:])[:10]
+	// note: object names must not exceed 253 characters
+	if len(ownerName) > 253-len(suffix)-1 {
+		ownerName = strings.TrimRight(ownerName[:253-len(suffix)-1], ".-")
+	}
+	return ownerName + "-" + suffix
+}
+
+// create or update masquerading rule through server-side apply; on success, masqueradingRule is updated with the state returned by the server
+func applyMasqueradingRule(ctx context.Context, c client.Client, masqueradingRule *dnsv1alpha1.MasqueradingRule) error {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(masqueradingRule)
+	if err != nil {
+		return err
+	}
+	obj := &unstructured.Unstructured{Object: content}
+	unstructured.RemoveNestedField(obj.Object, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(obj.Object, "status")
+	if err := c.Apply(ctx, client.ApplyConfigurationFromUnstructured(obj), client.FieldOwner(fieldOwner), client.ForceOwnership); err != nil {
+		return err
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, masqueradingRule)
+}
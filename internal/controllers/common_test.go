@@ -0,0 +1,275 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	dnsv1alpha1 "github.com/sap/dns-masquerading-operator/api/v1alpha1"
+)
+
+// drain all events currently buffered in the given fake recorder
+func drainEvents(recorder *record.FakeRecorder) []string {
+	var events []string
+	for {
+		select {
+		case event := <-recorder.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestGetMappedTargetsEvents(t *testing.T) {
+	recorder := record.NewFakeRecorder(100)
+	obj := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test", UID: types.UID("test-uid")}}
+	hosts := []string{"www.example.io", "api.example.io"}
+
+	// note: repeated events are not suppressed here, but aggregated by the (real) event recorder
+	steps := []struct {
+		name       string
+		annotation string
+		targets    map[string]string
+		events     int
+	}{
+		{name: "problems are reported", annotation: "www.example.io=1.2.3.4,other.example.io=5.6.7.8", targets: map[string]string{"www.example.io": "1.2.3.4"}, events: 1},
+		{name: "same problems are reported again", annotation: "www.example.io=1.2.3.4,other.example.io=5.6.7.8", targets: map[string]string{"www.example.io": "1.2.3.4"}, events: 1},
+		{name: "each problem is reported", annotation: "www.example.io=1.2.3.4,other.example.io=5.6.7.8,api.example.io=-", targets: map[string]string{"www.example.io": "1.2.3.4"}, events: 2},
+		{name: "no problems", annotation: "www.example.io=1.2.3.4", targets: map[string]string{"www.example.io": "1.2.3.4"}, events: 0},
+		{name: "no annotation", annotation: "", events: 0},
+		{name: "syntax error is reported", annotation: "www.example.io", events: 1},
+	}
+	for _, step := range steps {
+		obj.Annotations = map[string]string{annotationMasqueradeMap: step.annotation}
+		targets := getMappedTargets(recorder, obj, hosts)
+		if len(targets) > 0 || len(step.targets) > 0 {
+			if !reflect.DeepEqual(targets, step.targets) {
+				t.Errorf("%s: got targets %v, want %v", step.name, targets, step.targets)
+			}
+		}
+		if events := drainEvents(recorder); len(events) != step.events {
+			t.Errorf("%s: got events %q, want %d events", step.name, events, step.events)
+		}
+	}
+}
+
+// reject applied masquerading rules if another (not deleted) rule with the same source exists (similar to the conflict check of the webhook)
+func rejectConflictingApply(ctx context.Context, c client.WithWatch, obj runtime.ApplyConfiguration, opts ...client.ApplyOption) error {
+	u, ok := obj.(interface{ UnstructuredContent() map[string]any })
+	if !ok {
+		return fmt.Errorf("unexpected apply configuration %T", obj)
+	}
+	rule := &unstructured.Unstructured{Object: u.UnstructuredContent()}
+	from, _, _ := unstructured.NestedString(rule.Object, "spec", "from")
+	masqueradingRuleList := &dnsv1alpha1.MasqueradingRuleList{}
+	if err := c.List(ctx, masqueradingRuleList, client.InNamespace(rule.GetNamespace())); err != nil {
+		return err
+	}
+	for _, masqueradingRule := range masqueradingRuleList.Items {
+		if masqueradingRule.Name != rule.GetName() && masqueradingRule.Spec.From == from && masqueradingRule.DeletionTimestamp.IsZero() {
+			return fmt.Errorf("conflicting masquerading rule %s", masqueradingRule.Name)
+		}
+	}
+	return c.Apply(ctx, obj, opts...)
+}
+
+func TestManageDependentsWithTargets(t *testing.T) {
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test", UID: types.UID("test-uid")}}
+	// rule as created by earlier versions of this operator (generated name, no owner reference)
+	staleRule := &dnsv1alpha1.MasqueradingRule{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-x7k2p", Labels: map[string]string{labelControllerUid: string(service.UID)}},
+		Spec:       dnsv1alpha1.MasqueradingRuleSpec{From: "www.example.io", To: "old.example.io"},
+	}
+	c := newFakeClientBuilder(t).
+		WithObjects(service, staleRule).
+		WithInterceptorFuncs(interceptor.Funcs{Apply: rejectConflictingApply}).
+		Build()
+	gvk := corev1.SchemeGroupVersion.WithKind("Service")
+
+	listRules := func() map[string]string {
+		masqueradingRuleList := &dnsv1alpha1.MasqueradingRuleList{}
+		if err := c.List(context.Background(), masqueradingRuleList, client.InNamespace("default")); err != nil {
+			t.Fatalf("got unexpected error: %s", err)
+		}
+		rules := make(map[string]string)
+		for _, masqueradingRule := range masqueradingRuleList.Items {
+			rules[masqueradingRule.Name] = masqueradingRule.Spec.From + "=" + masqueradingRule.Spec.To
+		}
+		return rules
+	}
+
+	targets := map[string]string{"www.example.io": "new.example.io", "api.example.io": "1.2.3.4"}
+	if err := manageDependentsWithTargets(context.Background(), c, service, targets); err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	expected := map[string]string{
+		buildMasqueradingRuleName("test", gvk, "www.example.io"): "www.example.io=new.example.io",
+		buildMasqueradingRuleName("test", gvk, "api.example.io"): "api.example.io=1.2.3.4",
+	}
+	if rules := listRules(); !reflect.DeepEqual(rules, expected) {
+		t.Errorf("got rules %v, want %v", rules, expected)
+	}
+
+	targets = map[string]string{"www.example.io": "other.example.io"}
+	if err := manageDependentsWithTargets(context.Background(), c, service, targets); err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	expected = map[string]string{
+		buildMasqueradingRuleName("test", gvk, "www.example.io"): "www.example.io=other.example.io",
+	}
+	if rules := listRules(); !reflect.DeepEqual(rules, expected) {
+		t.Errorf("got rules %v, want %v", rules, expected)
+	}
+
+	if err := manageDependentsWithTargets(context.Background(), c, service, nil); err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	if rules := listRules(); len(rules) > 0 {
+		t.Errorf("got rules %v, want none", rules)
+	}
+}
+
+func TestUpdateMasqueradingStatus(t *testing.T) {
+	gvk := corev1.SchemeGroupVersion.WithKind("Service")
+	nameA := buildMasqueradingRuleName("test", gvk, "a.example.io")
+	nameB := buildMasqueradingRuleName("test", gvk, "b.example.io")
+	newRule := func(name string, from string, to string, generation int64, observedGeneration int64, state dnsv1alpha1.MasqueradingRuleState, message string) dnsv1alpha1.MasqueradingRule {
+		masqueradingRule := dnsv1alpha1.MasqueradingRule{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Generation: generation},
+			Spec:       dnsv1alpha1.MasqueradingRuleSpec{From: from, To: to},
+			Status:     dnsv1alpha1.MasqueradingRuleStatus{ObservedGeneration: observedGeneration, State: state},
+		}
+		if message != "" {
+			masqueradingRule.Status.Conditions = []metav1.Condition{{Type: dnsv1alpha1.MasqueradingRuleConditionTypeReady, Status: metav1.ConditionFalse, Message: message}}
+		}
+		return masqueradingRule
+	}
+
+	tests := []struct {
+		name              string
+		annotation        string
+		targets           map[string]string
+		masqueradingRules []dnsv1alpha1.MasqueradingRule
+		status            string
+	}{
+		{
+			name:    "all ready",
+			targets: map[string]string{"b.example.io": "2.2.2.2", "a.example.io": "1.1.1.1"},
+			masqueradingRules: []dnsv1alpha1.MasqueradingRule{
+				newRule(nameA, "a.example.io", "1.1.1.1", 1, 1, dnsv1alpha1.MasqueradingRuleStateReady, ""),
+				newRule(nameB, "b.example.io", "2.2.2.2", 2, 2, dnsv1alpha1.MasqueradingRuleStateReady, ""),
+			},
+			status: `{"ready":true,"hosts":[{"host":"a.example.io","to":"1.1.1.1","rule":"` + nameA + `","state":"Ready"},{"host":"b.example.io","to":"2.2.2.2","rule":"` + nameB + `","state":"Ready"}]}`,
+		},
+		{
+			name:    "rule without status",
+			targets: map[string]string{"a.example.io": "1.1.1.1"},
+			masqueradingRules: []dnsv1alpha1.MasqueradingRule{
+				newRule(nameA, "a.example.io", "1.1.1.1", 1, 0, "", ""),
+			},
+			status: `{"ready":false,"hosts":[{"host":"a.example.io","to":"1.1.1.1","rule":"` + nameA + `","state":"New"}]}`,
+		},
+		{
+			name:    "missing rule",
+			targets: map[string]string{"a.example.io": "1.1.1.1"},
+			status:  `{"ready":false,"hosts":[{"host":"a.example.io","to":"1.1.1.1","rule":"` + nameA + `","state":"New"}]}`,
+		},
+		{
+			name:    "status of outdated generation",
+			targets: map[string]string{"a.example.io": "1.1.1.1"},
+			masqueradingRules: []dnsv1alpha1.MasqueradingRule{
+				newRule(nameA, "a.example.io", "1.1.1.1", 2, 1, dnsv1alpha1.MasqueradingRuleStateReady, ""),
+			},
+			status: `{"ready":false,"hosts":[{"host":"a.example.io","to":"1.1.1.1","rule":"` + nameA + `","state":"Processing"}]}`,
+		},
+		{
+			name:    "rule with other target",
+			targets: map[string]string{"a.example.io": "1.1.1.1"},
+			masqueradingRules: []dnsv1alpha1.MasqueradingRule{
+				newRule(nameA, "a.example.io", "9.9.9.9", 1, 1, dnsv1alpha1.MasqueradingRuleStateReady, ""),
+			},
+			status: `{"ready":false,"hosts":[{"host":"a.example.io","to":"1.1.1.1","rule":"` + nameA + `","state":"New"}]}`,
+		},
+		{
+			name:    "error with message",
+			targets: map[string]string{"a.example.io": "1.1.1.1", "b.example.io": "2.2.2.2"},
+			masqueradingRules: []dnsv1alpha1.MasqueradingRule{
+				newRule(nameA, "a.example.io", "1.1.1.1", 1, 1, dnsv1alpha1.MasqueradingRuleStateReady, ""),
+				newRule(nameB, "b.example.io", "2.2.2.2", 1, 1, dnsv1alpha1.MasqueradingRuleStateError, "dns check failed"),
+			},
+			status: `{"ready":false,"hosts":[{"host":"a.example.io","to":"1.1.1.1","rule":"` + nameA + `","state":"Ready"},{"host":"b.example.io","to":"2.2.2.2","rule":"` + nameB + `","state":"Error","message":"dns check failed"}]}`,
+		},
+		{
+			name:       "no targets",
+			annotation: `{"ready":true,"hosts":[]}`,
+			status:     "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"}}
+			if tt.annotation != "" {
+				service.Annotations = map[string]string{annotationMasqueradingStatus: tt.annotation}
+			}
+			c := newFakeClient(t, service)
+			if err := updateMasqueradingStatus(context.Background(), c, service, gvk, tt.targets, tt.masqueradingRules); err != nil {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+			if err := c.Get(context.Background(), client.ObjectKeyFromObject(service), service); err != nil {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+			status, ok := service.Annotations[annotationMasqueradingStatus]
+			if status != tt.status || ok != (tt.status != "") {
+				t.Errorf("got status %q, want %q", status, tt.status)
+			}
+		})
+	}
+}
+
+func TestMasqueradingStatusPredicate(t *testing.T) {
+	p := masqueradingStatusPredicate()
+	old := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test", ResourceVersion: "1"}}
+
+	statusOnly := old.DeepCopy()
+	statusOnly.ResourceVersion = "2"
+	statusOnly.Annotations = map[string]string{annotationMasqueradingStatus: `{"ready":true}`}
+	if p.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: statusOnly}) {
+		t.Errorf("expected update of masquerading status to be skipped")
+	}
+	if p.Update(event.UpdateEvent{ObjectOld: statusOnly, ObjectNew: old}) {
+		t.Errorf("expected removal of masquerading status to be skipped")
+	}
+
+	annotated := statusOnly.DeepCopy()
+	annotated.Annotations[annotationMasqueradeTo] = "1.2.3.4"
+	if !p.Update(event.UpdateEvent{ObjectOld: statusOnly, ObjectNew: annotated}) {
+		t.Errorf("expected update of other annotations to pass")
+	}
+
+	loadBalanced := statusOnly.DeepCopy()
+	loadBalanced.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "1.2.3.4"}}
+	if !p.Update(event.UpdateEvent{ObjectOld: statusOnly, ObjectNew: loadBalanced}) {
+		t.Errorf("expected update of status to pass")
+	}
+
+	if !p.Create(event.CreateEvent{Object: statusOnly}) || !p.Delete(event.DeleteEvent{Object: statusOnly}) {
+		t.Errorf("expected create and delete events to pass")
+	}
+}
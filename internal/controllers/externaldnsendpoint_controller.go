@@ -0,0 +1,107 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dnsv1alpha1 "github.com/sap/dns-masquerading-operator/api/v1alpha1"
+)
+
+// Group version kind of external-dns dns endpoints
+var DNSEndpointGroupVersionKind = schema.GroupVersionKind{Group: "externaldns.k8s.io", Version: "v1alpha1", Kind: "DNSEndpoint"}
+
+// DNSEndpointReconciler reconciles a DNSEndpoint object (of external-dns)
+type DNSEndpointReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	Filter   *SourceFilter
+}
+
+// Reconcile a dns endpoint resource
+func (r *DNSEndpointReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	log := ctrl.LoggerFrom(ctx)
+	log.V(1).Info("running reconcile")
+
+	// Retrieve target dns endpoint
+	dnsEndpoint := &unstructured.Unstructured{}
+	dnsEndpoint.SetGroupVersionKind(DNSEndpointGroupVersionKind)
+	if err := r.Get(ctx, req.NamespacedName, dnsEndpoint); err != nil {
+		if err := client.IgnoreNotFound(err); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "unexpected get error")
+		}
+		log.Info("not found; ignoring")
+		return ctrl.Result{}, nil
+	}
+
+	// Clean up masquerading rules of objects not matching the filter (any longer)
+	if !r.Filter.Matches(dnsEndpoint) {
+		log.V(1).Info("not matching filter; ignoring")
+		if err := manageDependentsWithTargets(ctx, r.Client, dnsEndpoint, nil); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	targets := make(map[string]string)
+	if dnsEndpoint.GetDeletionTimestamp().IsZero() {
+		targets, err = getRecordTargets(ctx, r.Client, r.Recorder, dnsEndpoint, getRecordsFromDNSEndpoint(dnsEndpoint))
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err := manageDependentsWithTargets(ctx, r.Client, dnsEndpoint, targets); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// getRecordsFromDNSEndpoint extracts the dns names of the A, AAAA and CNAME endpoints of a dns endpoint resource,
+// together with their first target (if a name occurs multiple times, the first occurrence wins)
+func getRecordsFromDNSEndpoint(dnsEndpoint *unstructured.Unstructured) map[string]string {
+	records := make(map[string]string)
+	endpoints, _, _ := unstructured.NestedSlice(dnsEndpoint.Object, "spec", "endpoints")
+	for _, endpoint := range endpoints {
+		endpoint, ok := endpoint.(map[string]any)
+		if !ok {
+			continue
+		}
+		dnsName, _, _ := unstructured.NestedString(endpoint, "dnsName")
+		recordType, _, _ := unstructured.NestedString(endpoint, "recordType")
+		targets, _, _ := unstructured.NestedStringSlice(endpoint, "targets")
+		if dnsName == "" || len(targets) == 0 || (recordType != "A" && recordType != "AAAA" && recordType != "CNAME") {
+			continue
+		}
+		if _, ok := records[normalizeHost(dnsName)]; !ok {
+			records[normalizeHost(dnsName)] = normalizeHost(targets[0])
+		}
+	}
+	return records
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *DNSEndpointReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	dnsEndpoint := &unstructured.Unstructured{}
+	dnsEndpoint.SetGroupVersionKind(DNSEndpointGroupVersionKind)
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("dnsendpoint").
+		For(dnsEndpoint, builder.WithPredicates(r.Filter.Predicate(), masqueradingStatusPredicate())).
+		Owns(&dnsv1alpha1.MasqueradingRule{}, builder.WithPredicates(masqueradingRulePredicate())).
+		Complete(r)
+}
@@ -0,0 +1,109 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestGetRecordsFromDNSEndpoint(t *testing.T) {
+	endpoints := []any{
+		map[string]any{"dnsName": "www.example.io", "recordType": "CNAME", "targets": []any{"LB.example.io."}},
+		map[string]any{"dnsName": "api.example.io", "recordType": "A", "targets": []any{"10.0.0.1", "10.0.0.2"}},
+		map[string]any{"dnsName": "API.example.io", "recordType": "AAAA", "targets": []any{"2001:db8::1"}},
+		map[string]any{"dnsName": "v6.example.io", "recordType": "AAAA", "targets": []any{"2001:db8::2"}},
+		map[string]any{"dnsName": "txt.example.io", "recordType": "TXT", "targets": []any{"foo"}},
+		map[string]any{"dnsName": "mx.example.io", "recordType": "MX", "targets": []any{"10 mail.example.io"}},
+		map[string]any{"dnsName": "empty.example.io", "recordType": "A", "targets": []any{}},
+		map[string]any{"recordType": "A", "targets": []any{"10.0.0.3"}},
+		"invalid",
+	}
+	dnsEndpoint := &unstructured.Unstructured{Object: map[string]any{"spec": map[string]any{"endpoints": endpoints}}}
+	dnsEndpoint.SetGroupVersionKind(DNSEndpointGroupVersionKind)
+
+	expected := map[string]string{
+		"www.example.io": "lb.example.io",
+		"api.example.io": "10.0.0.1",
+		"v6.example.io":  "2001:db8::2",
+	}
+	if records := getRecordsFromDNSEndpoint(dnsEndpoint); !reflect.DeepEqual(records, expected) {
+		t.Errorf("got records %v, want %v", records, expected)
+	}
+}
+
+func TestGetRecordTargets(t *testing.T) {
+	records := map[string]string{
+		"www.example.io": "lb.example.io",
+		"api.example.io": "10.0.0.1",
+	}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		targets     map[string]string
+	}{
+		{
+			name:    "targets from spec",
+			targets: map[string]string{"www.example.io": "lb.example.io", "api.example.io": "10.0.0.1"},
+		},
+		{
+			name:        "target override annotation",
+			annotations: map[string]string{annotationMasqueradeTo: "istio-ingressgateway.istio-system.svc.cluster.local"},
+			targets:     map[string]string{"www.example.io": "istio-ingressgateway.istio-system.svc.cluster.local", "api.example.io": "istio-ingressgateway.istio-system.svc.cluster.local"},
+		},
+		{
+			name:        "legacy target override annotation",
+			annotations: map[string]string{annotationMasqueradeToLegacy: "legacy.example.io"},
+			targets:     map[string]string{"www.example.io": "legacy.example.io", "api.example.io": "legacy.example.io"},
+		},
+		{
+			name:        "target override annotation takes precedence over legacy annotation",
+			annotations: map[string]string{annotationMasqueradeTo: "new.example.io", annotationMasqueradeToLegacy: "legacy.example.io"},
+			targets:     map[string]string{"www.example.io": "new.example.io", "api.example.io": "new.example.io"},
+		},
+		{
+			name:        "masquerade map takes precedence over override",
+			annotations: map[string]string{annotationMasqueradeTo: "new.example.io", annotationMasqueradeMap: "api.example.io=10.0.0.9"},
+			targets:     map[string]string{"www.example.io": "new.example.io", "api.example.io": "10.0.0.9"},
+		},
+		{
+			name:        "excluded hosts",
+			annotations: map[string]string{annotationMasqueradeExclude: "api.example.io"},
+			targets:     map[string]string{"www.example.io": "lb.example.io"},
+		},
+		{
+			name:        "additional hosts without target are dropped",
+			annotations: map[string]string{annotationMasqueradeFrom: "other.example.io"},
+			targets:     map[string]string{"www.example.io": "lb.example.io", "api.example.io": "10.0.0.1"},
+		},
+		{
+			name:        "additional hosts with override",
+			annotations: map[string]string{annotationMasqueradeFrom: "other.example.io", annotationMasqueradeTo: "new.example.io"},
+			targets:     map[string]string{"www.example.io": "new.example.io", "api.example.io": "new.example.io", "other.example.io": "new.example.io"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dnsEndpoint := &unstructured.Unstructured{Object: map[string]any{}}
+			dnsEndpoint.SetGroupVersionKind(DNSEndpointGroupVersionKind)
+			dnsEndpoint.SetNamespace("default")
+			dnsEndpoint.SetName("test")
+			dnsEndpoint.SetAnnotations(tt.annotations)
+			targets, err := getRecordTargets(context.Background(), newFakeClient(t), record.NewFakeRecorder(10), dnsEndpoint, records)
+			if err != nil {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(targets, tt.targets) {
+				t.Errorf("got targets %v, want %v", targets, tt.targets)
+			}
+		})
+	}
+}
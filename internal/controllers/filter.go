@@ -0,0 +1,239 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sap/go-generics/slices"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	dnsv1alpha1 "github.com/sap/dns-masquerading-operator/api/v1alpha1"
+)
+
+// SourceFilter restricts the objects processed by a source controller; a nil filter matches all objects
+type SourceFilter struct {
+	// Namespaces to be processed; empty means all namespaces
+	Namespaces []string
+	// Namespaces to be skipped
+	ExcludedNamespaces []string
+	// Label selector objects must match; nil means that all objects match
+	Selector labels.Selector
+}
+
+// Create SourceFilter from comma-separated lists of namespaces and excluded namespaces, and a label selector (all optional);
+// returns nil if all parameters are empty
+func ParseSourceFilter(namespaces string, excludedNamespaces string, selector string) (*SourceFilter, error) {
+	if namespaces == "" && excludedNamespaces == "" && selector == "" {
+		return nil, nil
+	}
+	f := &SourceFilter{
+		Namespaces:         splitList(namespaces),
+		ExcludedNamespaces: splitList(excludedNamespaces),
+	}
+	if selector != "" {
+		s, err := labels.Parse(selector)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid label selector %s", selector)
+		}
+		f.Selector = s
+	}
+	return f, nil
+}
+
+// Check if given object matches the filter
+func (f *SourceFilter) Matches(obj client.Object) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.Namespaces) > 0 && !slices.Contains(f.Namespaces, obj.GetNamespace()) {
+		return false
+	}
+	if slices.Contains(f.ExcludedNamespaces, obj.GetNamespace()) {
+		return false
+	}
+	if f.Selector != nil && !f.Selector.Matches(labels.Set(obj.GetLabels())) {
+		return false
+	}
+	return true
+}
+
+// Return predicate for the filter; update events pass if either the old or the new object matches
+// (such that masquerading rules of objects no longer matching the filter can be cleaned up)
+func (f *SourceFilter) Predicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return f.Matches(e.Object) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return f.Matches(e.ObjectNew) || f.Matches(e.ObjectOld) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return f.Matches(e.Object) },
+		GenericFunc: func(e event.GenericEvent) bool { return f.Matches(e.Object) },
+	}
+}
+
+// Return cache restrictions for the filter (to be used in the cache options of the manager)
+func (f *SourceFilter) CacheConfig() cache.ByObject {
+	var byObject cache.ByObject
+	if f == nil {
+		return byObject
+	}
+	if len(f.Namespaces) > 0 {
+		byObject.Namespaces = make(map[string]cache.Config)
+		for _, namespace := range f.Namespaces {
+			if !slices.Contains(f.ExcludedNamespaces, namespace) {
+				byObject.Namespaces[namespace] = cache.Config{}
+			}
+		}
+	} else if len(f.ExcludedNamespaces) > 0 {
+		var selectors []fields.Selector
+		for _, namespace := range f.ExcludedNamespaces {
+			selectors = append(selectors, fields.OneTermNotEqualSelector("metadata.namespace", namespace))
+		}
+		byObject.Field = fields.AndSelectors(selectors...)
+	}
+	byObject.Label = f.Selector
+	return byObject
+}
+
+// FilterSweeper periodically deletes masquerading rules generated for objects which exist, but no longer match the filter of their
+// source controller; this is needed for kinds whose filter is applied to the informer cache as well, since objects dropping out of
+// the cache (e.g. because a label was removed) are never seen again by their source controller
+type FilterSweeper struct {
+	// Client used to list and delete masquerading rules
+	Client client.Client
+	// Reader used to retrieve the owners of masquerading rules; must not be restricted by the source filters (e.g. the API reader of the manager)
+	Reader client.Reader
+	// Source filters, by kind of the source objects
+	Filters map[schema.GroupKind]*SourceFilter
+	// Interval between two sweeps (the first sweep runs immediately)
+	Interval time.Duration
+}
+
+// Start the sweeper; implements manager.Runnable
+func (s *FilterSweeper) Start(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx).WithName("filter-sweeper")
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Sweep(ctrl.LoggerInto(ctx, log)); err != nil {
+			log.Error(err, "error sweeping masquerading rules of objects not matching the filter")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Run the sweeper only on the leader; implements manager.LeaderElectionRunnable
+func (s *FilterSweeper) NeedLeaderElection() bool {
+	return true
+}
+
+// Delete masquerading rules whose owner exists, but does not match the filter of its kind
+func (s *FilterSweeper) Sweep(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	masqueradingRuleList := &dnsv1alpha1.MasqueradingRuleList{}
+	if err := s.Client.List(ctx, masqueradingRuleList, client.HasLabels{labelControllerUid}); err != nil {
+		return errors.Wrap(err, "failed to list generated masquerading rules")
+	}
+
+	var errs []error
+	for _, masqueradingRule := range masqueradingRuleList.Items {
+		if !masqueradingRule.DeletionTimestamp.IsZero() {
+			continue
+		}
+		labels := masqueradingRule.Labels
+		filter, ok := s.Filters[schema.GroupKind{Group: labels[labelControllerGroup], Kind: labels[labelControllerKind]}]
+		if !ok || filter == nil {
+			continue
+		}
+		owner := &metav1.PartialObjectMetadata{}
+		owner.SetGroupVersionKind(schema.GroupVersionKind{Group: labels[labelControllerGroup], Version: labels[labelControllerVersion], Kind: labels[labelControllerKind]})
+		if err := s.Reader.Get(ctx, types.NamespacedName{Namespace: masqueradingRule.Namespace, Name: labels[labelControllerName]}, owner); err != nil {
+			// note: rules of deleted owners are garbage-collected
+			if client.IgnoreNotFound(err) != nil {
+				errs = append(errs, errors.Wrapf(err, "failed to retrieve owner of masquerading rule %s/%s", masqueradingRule.Namespace, masqueradingRule.Name))
+			}
+			continue
+		}
+		if string(owner.UID) != labels[labelControllerUid] || filter.Matches(owner) {
+			continue
+		}
+		if err := s.Client.Delete(ctx, &masqueradingRule, client.PropagationPolicy(metav1.DeletePropagationForeground)); client.IgnoreNotFound(err) != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to delete masquerading rule %s/%s", masqueradingRule.Namespace, masqueradingRule.Name))
+			continue
+		}
+		log.Info("deleted masquerading rule of object not matching the filter", "namespace", masqueradingRule.Namespace, "name", masqueradingRule.Name)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// split comma-separated list, trimming and dropping empty elements
+func splitList(s string) []string {
+	var result []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// ClassFilter restricts the objects processed by a source controller to certain classes (such as ingress classes, gateway classes,
+// or labels of istio gateway selectors), mapping each class to a default target (which may be empty); a nil filter matches all classes
+type ClassFilter map[string]string
+
+// Create ClassFilter from a comma-separated list of entries of the form class[:target]; returns nil if the list is empty
+func ParseClassFilter(s string) (ClassFilter, error) {
+	items := splitList(s)
+	if len(items) == 0 {
+		return nil, nil
+	}
+	f := make(ClassFilter)
+	for _, item := range items {
+		// note: split at the first colon only, since targets may be IPv6 addresses
+		class, target, _ := strings.Cut(item, ":")
+		class = strings.TrimSpace(class)
+		target = strings.TrimSpace(target)
+		if class == "" {
+			return nil, errors.Errorf("invalid class filter entry %s (empty class)", item)
+		}
+		if t, ok := f[class]; ok && t != target {
+			return nil, errors.Errorf("invalid class filter entry %s (conflicting targets for class %s)", item, class)
+		}
+		f[class] = target
+	}
+	return f, nil
+}
+
+// Check if any of the given classes matches the filter; returns the default target of the first matching class
+// (in the order of the given classes); if the filter is nil, always returns true (and an empty target)
+func (f ClassFilter) Match(classes ...string) (string, bool) {
+	if f == nil {
+		return "", true
+	}
+	for _, class := range classes {
+		if target, ok := f[class]; ok {
+			return target, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,311 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	dnsv1alpha1 "github.com/sap/dns-masquerading-operator/api/v1alpha1"
+)
+
+func TestParseSourceFilter(t *testing.T) {
+	tests := []struct {
+		name               string
+		namespaces         string
+		excludedNamespaces string
+		selector           string
+		filter             *SourceFilter
+		err                bool
+	}{
+		{name: "empty", filter: nil},
+		{name: "namespaces", namespaces: " a, b,,c ", filter: &SourceFilter{Namespaces: []string{"a", "b", "c"}}},
+		{name: "excluded namespaces", excludedNamespaces: "kube-system", filter: &SourceFilter{ExcludedNamespaces: []string{"kube-system"}}},
+		{name: "separators only", namespaces: ",", filter: &SourceFilter{}},
+		{name: "invalid selector", selector: "app in (", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := ParseSourceFilter(tt.namespaces, tt.excludedNamespaces, tt.selector)
+			if tt.err {
+				if err == nil {
+					t.Errorf("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(filter, tt.filter) {
+				t.Errorf("got filter %+v, want %+v", filter, tt.filter)
+			}
+		})
+	}
+
+	filter, err := ParseSourceFilter("", "", "app=test,tier!=db")
+	if err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	if filter == nil || filter.Selector == nil || filter.Selector.String() != "app=test,tier!=db" {
+		t.Errorf("got filter %+v, want selector %s", filter, "app=test,tier!=db")
+	}
+}
+
+func TestSourceFilterMatches(t *testing.T) {
+	newObject := func(namespace string, labels map[string]string) client.Object {
+		return &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "test", Labels: labels}}
+	}
+
+	tests := []struct {
+		name               string
+		namespaces         string
+		excludedNamespaces string
+		selector           string
+		object             client.Object
+		matches            bool
+	}{
+		{name: "no filter", object: newObject("a", nil), matches: true},
+		{name: "listed namespace", namespaces: "a,b", object: newObject("b", nil), matches: true},
+		{name: "unlisted namespace", namespaces: "a,b", object: newObject("c", nil), matches: false},
+		{name: "excluded namespace", excludedNamespaces: "a", object: newObject("a", nil), matches: false},
+		{name: "not excluded namespace", excludedNamespaces: "a", object: newObject("b", nil), matches: true},
+		{name: "listed and excluded namespace", namespaces: "a,b", excludedNamespaces: "a", object: newObject("a", nil), matches: false},
+		{name: "matching selector", selector: "app=test", object: newObject("a", map[string]string{"app": "test", "tier": "web"}), matches: true},
+		{name: "non-matching selector", selector: "app=test", object: newObject("a", map[string]string{"app": "other"}), matches: false},
+		{name: "selector without labels", selector: "app=test", object: newObject("a", nil), matches: false},
+		{name: "matching namespace, non-matching selector", namespaces: "a", selector: "app=test", object: newObject("a", nil), matches: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := ParseSourceFilter(tt.namespaces, tt.excludedNamespaces, tt.selector)
+			if err != nil {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+			if matches := filter.Matches(tt.object); matches != tt.matches {
+				t.Errorf("got %t, want %t", matches, tt.matches)
+			}
+		})
+	}
+}
+
+func TestSourceFilterPredicate(t *testing.T) {
+	filter, err := ParseSourceFilter("", "", "app=test")
+	if err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	p := filter.Predicate()
+	matching := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "a", Name: "test", Labels: map[string]string{"app": "test"}}}
+	other := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "a", Name: "test"}}
+
+	if !p.Create(event.CreateEvent{Object: matching}) || p.Create(event.CreateEvent{Object: other}) {
+		t.Errorf("expected create events to pass for matching objects only")
+	}
+	if !p.Delete(event.DeleteEvent{Object: matching}) || p.Delete(event.DeleteEvent{Object: other}) {
+		t.Errorf("expected delete events to pass for matching objects only")
+	}
+	if !p.Generic(event.GenericEvent{Object: matching}) || p.Generic(event.GenericEvent{Object: other}) {
+		t.Errorf("expected generic events to pass for matching objects only")
+	}
+	if !p.Update(event.UpdateEvent{ObjectOld: matching, ObjectNew: matching}) {
+		t.Errorf("expected update event to pass if both objects match")
+	}
+	if !p.Update(event.UpdateEvent{ObjectOld: matching, ObjectNew: other}) {
+		t.Errorf("expected update event to pass if the object stops matching")
+	}
+	if !p.Update(event.UpdateEvent{ObjectOld: other, ObjectNew: matching}) {
+		t.Errorf("expected update event to pass if the object starts matching")
+	}
+	if p.Update(event.UpdateEvent{ObjectOld: other, ObjectNew: other}) {
+		t.Errorf("expected update event not to pass if neither object matches")
+	}
+
+	// nil filter matches everything
+	var nilFilter *SourceFilter
+	if !nilFilter.Predicate().Create(event.CreateEvent{Object: other}) {
+		t.Errorf("expected nil filter to match all objects")
+	}
+}
+
+func TestSourceFilterCacheConfig(t *testing.T) {
+	var nilFilter *SourceFilter
+	if byObject := nilFilter.CacheConfig(); !reflect.DeepEqual(byObject, cache.ByObject{}) {
+		t.Errorf("got %+v for nil filter, want empty config", byObject)
+	}
+
+	filter, err := ParseSourceFilter("a,b", "b", "app=test")
+	if err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	byObject := filter.CacheConfig()
+	if !reflect.DeepEqual(byObject.Namespaces, map[string]cache.Config{"a": {}}) {
+		t.Errorf("got namespaces %v, want %v", byObject.Namespaces, []string{"a"})
+	}
+	if byObject.Field != nil {
+		t.Errorf("got field selector %s, want none", byObject.Field)
+	}
+	if byObject.Label == nil || byObject.Label.String() != "app=test" {
+		t.Errorf("got label selector %v, want %s", byObject.Label, "app=test")
+	}
+
+	filter, err = ParseSourceFilter("", "kube-system,istio-system", "")
+	if err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	byObject = filter.CacheConfig()
+	if byObject.Namespaces != nil || byObject.Label != nil {
+		t.Errorf("got namespaces %v and label selector %v, want none", byObject.Namespaces, byObject.Label)
+	}
+	if byObject.Field == nil {
+		t.Fatalf("got no field selector, want one")
+	}
+	for namespace, matches := range map[string]bool{"kube-system": false, "istio-system": false, "default": true} {
+		if m := byObject.Field.Matches(fields.Set{"metadata.namespace": namespace}); m != matches {
+			t.Errorf("got %t for namespace %s, want %t", m, namespace, matches)
+		}
+	}
+}
+
+func TestFilterSweeperSweep(t *testing.T) {
+	newService := func(name string, uid string, labels map[string]string) *corev1.Service {
+		return &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID(uid), Labels: labels}}
+	}
+	newRule := func(name string, ownerKind string, ownerName string, ownerUid string) *dnsv1alpha1.MasqueradingRule {
+		return &dnsv1alpha1.MasqueradingRule{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      name,
+				Labels: map[string]string{
+					labelControllerGroup:   "",
+					labelControllerVersion: "v1",
+					labelControllerKind:    ownerKind,
+					labelControllerName:    ownerName,
+					labelControllerUid:     ownerUid,
+				},
+			},
+			Spec: dnsv1alpha1.MasqueradingRuleSpec{From: name + ".example.io", To: "1.2.3.4"},
+		}
+	}
+	manualRule := &dnsv1alpha1.MasqueradingRule{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "manual"},
+		Spec:       dnsv1alpha1.MasqueradingRuleSpec{From: "manual.example.io", To: "1.2.3.4"},
+	}
+
+	c := newFakeClient(t,
+		newService("matching", "uid-1", map[string]string{"app": "test"}),
+		newService("unlabeled", "uid-2", nil),
+		newService("recreated", "uid-4", nil),
+		newRule("matching", "Service", "matching", "uid-1"),
+		newRule("unlabeled", "Service", "unlabeled", "uid-2"),
+		newRule("deleted", "Service", "deleted", "uid-3"),
+		newRule("recreated", "Service", "recreated", "uid-3"),
+		newRule("other-kind", "ConfigMap", "unlabeled", "uid-2"),
+		manualRule,
+	)
+	filter, err := ParseSourceFilter("", "", "app=test")
+	if err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	sweeper := &FilterSweeper{
+		Client:  c,
+		Reader:  c,
+		Filters: map[schema.GroupKind]*SourceFilter{{Kind: "Service"}: filter},
+	}
+	if err := sweeper.Sweep(context.Background()); err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+
+	masqueradingRuleList := &dnsv1alpha1.MasqueradingRuleList{}
+	if err := c.List(context.Background(), masqueradingRuleList); err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	var names []string
+	for _, masqueradingRule := range masqueradingRuleList.Items {
+		names = append(names, masqueradingRule.Name)
+	}
+	expected := []string{"deleted", "manual", "matching", "other-kind", "recreated"}
+	if names := sorted(names); !reflect.DeepEqual(names, expected) {
+		t.Errorf("got rules %v, want %v", names, expected)
+	}
+}
+
+func TestParseClassFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		filter ClassFilter
+		err    bool
+	}{
+		{name: "empty", value: "", filter: nil},
+		{name: "separators only", value: " , ,", filter: nil},
+		{name: "classes without target", value: "nginx, istio", filter: ClassFilter{"nginx": "", "istio": ""}},
+		{name: "classes with target", value: "nginx:nginx.ingress.svc.cluster.local,internal: 10.0.0.1 ", filter: ClassFilter{"nginx": "nginx.ingress.svc.cluster.local", "internal": "10.0.0.1"}},
+		{name: "empty target", value: "nginx:", filter: ClassFilter{"nginx": ""}},
+		{name: "ipv6 target", value: "nginx:fd00::1,istio:2001:db8::10", filter: ClassFilter{"nginx": "fd00::1", "istio": "2001:db8::10"}},
+		{name: "selector label with target", value: "istio=ingressgateway:gw.istio-system.svc.cluster.local", filter: ClassFilter{"istio=ingressgateway": "gw.istio-system.svc.cluster.local"}},
+		{name: "identical duplicates", value: "nginx:10.0.0.1,nginx:10.0.0.1,istio,istio", filter: ClassFilter{"nginx": "10.0.0.1", "istio": ""}},
+		{name: "conflicting duplicates", value: "nginx:10.0.0.1,nginx:10.0.0.2", err: true},
+		{name: "conflicting duplicates with and without target", value: "nginx,nginx:10.0.0.1", err: true},
+		{name: "conflicting ipv6 duplicates", value: "nginx:fd00::1,nginx:fd00::2", err: true},
+		{name: "empty class", value: ":10.0.0.1", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := ParseClassFilter(tt.value)
+			if tt.err {
+				if err == nil {
+					t.Errorf("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(filter, tt.filter) {
+				t.Errorf("got filter %v, want %v", filter, tt.filter)
+			}
+		})
+	}
+}
+
+func TestClassFilterMatch(t *testing.T) {
+	filter := ClassFilter{"nginx": "", "internal": "internal.example.io", "app=gw": "fd00::1"}
+
+	tests := []struct {
+		name    string
+		filter  ClassFilter
+		classes []string
+		target  string
+		matches bool
+	}{
+		{name: "nil filter", filter: nil, classes: []string{"nginx"}, target: "", matches: true},
+		{name: "nil filter without classes", filter: nil, target: "", matches: true},
+		{name: "class without target", filter: filter, classes: []string{"nginx"}, target: "", matches: true},
+		{name: "class with target", filter: filter, classes: []string{"internal"}, target: "internal.example.io", matches: true},
+		{name: "class with ipv6 target", filter: filter, classes: []string{"app=gw"}, target: "fd00::1", matches: true},
+		{name: "unknown class", filter: filter, classes: []string{"other"}, target: "", matches: false},
+		{name: "no classes", filter: filter, target: "", matches: false},
+		{name: "first matching class wins", filter: filter, classes: []string{"other", "internal", "app=gw"}, target: "internal.example.io", matches: true},
+		{name: "first matching class wins even without target", filter: filter, classes: []string{"nginx", "internal"}, target: "", matches: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, matches := tt.filter.Match(tt.classes...)
+			if target != tt.target || matches != tt.matches {
+				t.Errorf("got %q, %t, want %q, %t", target, matches, tt.target, tt.matches)
+			}
+		})
+	}
+}
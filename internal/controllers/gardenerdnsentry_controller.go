@@ -0,0 +1,95 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dnsv1alpha1 "github.com/sap/dns-masquerading-operator/api/v1alpha1"
+)
+
+// Group version kind of gardener dns entries
+var DNSEntryGroupVersionKind = schema.GroupVersionKind{Group: "dns.gardener.cloud", Version: "v1alpha1", Kind: "DNSEntry"}
+
+// DNSEntryReconciler reconciles a DNSEntry object (of the gardener dns controller manager)
+type DNSEntryReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	Filter   *SourceFilter
+}
+
+// Reconcile a dns entry resource
+func (r *DNSEntryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	log := ctrl.LoggerFrom(ctx)
+	log.V(1).Info("running reconcile")
+
+	// Retrieve target dns entry
+	dnsEntry := &unstructured.Unstructured{}
+	dnsEntry.SetGroupVersionKind(DNSEntryGroupVersionKind)
+	if err := r.Get(ctx, req.NamespacedName, dnsEntry); err != nil {
+		if err := client.IgnoreNotFound(err); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "unexpected get error")
+		}
+		log.Info("not found; ignoring")
+		return ctrl.Result{}, nil
+	}
+
+	// Clean up masquerading rules of objects not matching the filter (any longer)
+	if !r.Filter.Matches(dnsEntry) {
+		log.V(1).Info("not matching filter; ignoring")
+		if err := manageDependentsWithTargets(ctx, r.Client, dnsEntry, nil); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	targets := make(map[string]string)
+	if dnsEntry.GetDeletionTimestamp().IsZero() {
+		targets, err = getRecordTargets(ctx, r.Client, r.Recorder, dnsEntry, getRecordsFromDNSEntry(dnsEntry))
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err := manageDependentsWithTargets(ctx, r.Client, dnsEntry, targets); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// getRecordsFromDNSEntry extracts the dns name of a dns entry resource, together with its first target
+func getRecordsFromDNSEntry(dnsEntry *unstructured.Unstructured) map[string]string {
+	records := make(map[string]string)
+	dnsName, _, _ := unstructured.NestedString(dnsEntry.Object, "spec", "dnsName")
+	targets, _, _ := unstructured.NestedStringSlice(dnsEntry.Object, "spec", "targets")
+	if dnsName != "" && len(targets) > 0 {
+		records[normalizeHost(dnsName)] = normalizeHost(targets[0])
+	}
+	return records
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *DNSEntryReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	dnsEntry := &unstructured.Unstructured{}
+	dnsEntry.SetGroupVersionKind(DNSEntryGroupVersionKind)
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("dnsentry").
+		For(dnsEntry, builder.WithPredicates(r.Filter.Predicate(), masqueradingStatusPredicate())).
+		Owns(&dnsv1alpha1.MasqueradingRule{}, builder.WithPredicates(masqueradingRulePredicate())).
+		Complete(r)
+}
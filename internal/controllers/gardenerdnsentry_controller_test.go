@@ -0,0 +1,56 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestGetRecordsFromDNSEntry(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    map[string]any
+		records map[string]string
+	}{
+		{
+			name:    "first target",
+			spec:    map[string]any{"dnsName": "WWW.Example.io.", "targets": []any{"LB.example.io.", "lb2.example.io"}},
+			records: map[string]string{"www.example.io": "lb.example.io"},
+		},
+		{
+			name:    "ip address target",
+			spec:    map[string]any{"dnsName": "www.example.io", "targets": []any{"10.0.0.1"}},
+			records: map[string]string{"www.example.io": "10.0.0.1"},
+		},
+		{
+			name:    "wildcard name",
+			spec:    map[string]any{"dnsName": "*.example.io", "targets": []any{"lb.example.io"}},
+			records: map[string]string{"*.example.io": "lb.example.io"},
+		},
+		{
+			name:    "no targets (e.g. text records)",
+			spec:    map[string]any{"dnsName": "www.example.io", "text": []any{"foo"}},
+			records: map[string]string{},
+		},
+		{
+			name:    "no dns name",
+			spec:    map[string]any{"targets": []any{"lb.example.io"}},
+			records: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dnsEntry := &unstructured.Unstructured{Object: map[string]any{"spec": tt.spec}}
+			dnsEntry.SetGroupVersionKind(DNSEntryGroupVersionKind)
+			if records := getRecordsFromDNSEntry(dnsEntry); !reflect.DeepEqual(records, tt.records) {
+				t.Errorf("got records %v, want %v", records, tt.records)
+			}
+		})
+	}
+}
@@ -0,0 +1,363 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/sap/go-generics/maps"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	dnsv1alpha1 "github.com/sap/dns-masquerading-operator/api/v1alpha1"
+)
+
+// KubernetesGatewayReconciler reconciles a Gateway object of the Kubernetes Gateway API
+type KubernetesGatewayReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	Recorder      record.EventRecorder
+	Filter        *SourceFilter
+	Classes       ClassFilter
+	ClusterDomain string
+}
+
+// Reconcile a gateway resource
+func (r *KubernetesGatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	log := ctrl.LoggerFrom(ctx)
+	log.V(1).Info("running reconcile")
+
+	// Retrieve target gateway
+	gateway := &gatewayv1.Gateway{}
+	if err := r.Get(ctx, req.NamespacedName, gateway); err != nil {
+		if err := client.IgnoreNotFound(err); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "unexpected get error")
+		}
+		log.Info("not found; ignoring")
+		return ctrl.Result{}, nil
+	}
+
+	// Check filters (including the gateway class filter, which may provide a default target)
+	classTarget, matches := "", r.Filter.Matches(gateway)
+	if matches {
+		classTarget, matches = r.Classes.Match(string(gateway.Spec.GatewayClassName))
+	}
+
+	// Clean up masquerading rules of objects not matching the filters (any longer)
+	if !matches {
+		log.V(1).Info("not matching filter; ignoring")
+		if err := manageDependentsWithTargets(ctx, r.Client, gateway, nil); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	defaultTo := ""
+	if autoTargetEnabled(gateway) {
+		defaultTo = classTarget
+		if defaultTo == "" {
+			defaultTo, err = getKubernetesGatewayTarget(ctx, r.Client, gateway, r.ClusterDomain)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	}
+
+	if err := manageDependents(ctx, r.Client, r.Recorder, gateway, getHosts(ctx, gateway, getHostsFromKubernetesGateway(gateway)...), defaultTo); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// getHostsFromKubernetesGateway extracts hosts of a gateway resource (from its listeners)
+func getHostsFromKubernetesGateway(gateway *gatewayv1.Gateway) []string {
+	hosts := make(map[string]struct{})
+	for _, listener := range gateway.Spec.Listeners {
+		if listener.Hostname != nil && *listener.Hostname != "" {
+			hosts[string(*listener.Hostname)] = struct{}{}
+		}
+	}
+	return maps.Keys(hosts)
+}
+
+// getKubernetesGatewayTarget determines the in-cluster address of a gateway; that is, the service generated
+// for the gateway by the implementation (identified by the well-known gateway-name label), or, if there is none,
+// the first address of type Hostname reported in the gateway's status; returns an empty string if nothing was found
+func getKubernetesGatewayTarget(ctx context.Context, c client.Client, gateway *gatewayv1.Gateway, clusterDomain string) (string, error) {
+	serviceList := &corev1.ServiceList{}
+	if err := c.List(ctx, serviceList, client.InNamespace(gateway.Namespace), client.MatchingLabels{gatewayv1.GatewayNameLabelKey: gateway.Name}); err != nil {
+		return "", errors.Wrap(err, "failed to list services of gateway")
+	}
+	if len(serviceList.Items) > 0 {
+		services := serviceList.Items
+		sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+		return getServiceAddress(&services[0], clusterDomain), nil
+	}
+	for _, address := range gateway.Status.Addresses {
+		if address.Type != nil && *address.Type == gatewayv1.HostnameAddressType {
+			return address.Value, nil
+		}
+	}
+	return "", nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *KubernetesGatewayReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// note: controller must be named explicitly, to avoid a name clash with the istio gateway controller
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("kubernetesgateway").
+		For(&gatewayv1.Gateway{}, builder.WithPredicates(r.Filter.Predicate(), masqueradingStatusPredicate())).
+		Owns(&dnsv1alpha1.MasqueradingRule{}, builder.WithPredicates(masqueradingRulePredicate())).
+		Watches(
+			&corev1.Service{},
+			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+				return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetLabels()[gatewayv1.GatewayNameLabelKey]}}}
+			}),
+			builder.WithPredicates(labelPredicate(gatewayv1.GatewayNameLabelKey)),
+		).
+		Complete(r)
+}
+
+const (
+	// field index of routes, holding the keys (namespace/name) of their parent gateways
+	routeParentGatewayIndex = "spec.parentRefs"
+)
+
+// RouteReconciler reconciles route objects of the Kubernetes Gateway API;
+// supported route types are HTTPRoute, GRPCRoute and TLSRoute
+type RouteReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	Recorder      record.EventRecorder
+	Filter        *SourceFilter
+	Classes       ClassFilter
+	ClusterDomain string
+	// Route type to be reconciled (e.g. &gatewayv1.HTTPRoute{})
+	Route client.Object
+}
+
+// Reconcile a route resource
+func (r *RouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	log := ctrl.LoggerFrom(ctx)
+	log.V(1).Info("running reconcile")
+
+	// Retrieve target route
+	route := r.Route.DeepCopyObject().(client.Object)
+	if err := r.Get(ctx, req.NamespacedName, route); err != nil {
+		if err := client.IgnoreNotFound(err); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "unexpected get error")
+		}
+		log.Info("not found; ignoring")
+		return ctrl.Result{}, nil
+	}
+
+	hostnames, parentRefs, err := getRouteSpec(route)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Check filters (routes match the gateway class filter if any of their parent gateways matches)
+	var gateways []*gatewayv1.Gateway
+	matches := r.Filter.Matches(route)
+	if matches && (r.Classes != nil || autoTargetEnabled(route)) {
+		gateways, err = getParentGateways(ctx, r.Client, route.GetNamespace(), parentRefs, r.Classes)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		matches = r.Classes == nil || len(gateways) > 0
+	}
+
+	// Clean up masquerading rules of objects not matching the filters (any longer)
+	if !matches {
+		log.V(1).Info("not matching filter; ignoring")
+		if err := manageDependentsWithTargets(ctx, r.Client, route, nil); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	defaultTo := ""
+	if autoTargetEnabled(route) {
+		defaultTo, err = getRouteTarget(ctx, r.Client, gateways, r.Classes, r.ClusterDomain)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err := manageDependents(ctx, r.Client, r.Recorder, route, getHosts(ctx, route, getHostsFromRoute(hostnames)...), defaultTo); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// getRouteSpec returns hostnames and parent references of a route resource
+func getRouteSpec(route client.Object) ([]gatewayv1.Hostname, []gatewayv1.ParentReference, error) {
+	switch route := route.(type) {
+	case *gatewayv1.HTTPRoute:
+		return route.Spec.Hostnames, route.Spec.ParentRefs, nil
+	case *gatewayv1.GRPCRoute:
+		return route.Spec.Hostnames, route.Spec.ParentRefs, nil
+	case *gatewayv1.TLSRoute:
+		return route.Spec.Hostnames, route.Spec.ParentRefs, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported route type %T", route)
+	}
+}
+
+// getHostsFromRoute extracts hosts of a route resource
+func getHostsFromRoute(hostnames []gatewayv1.Hostname) []string {
+	hosts := make(map[string]struct{})
+	for _, hostname := range hostnames {
+		if hostname != "" {
+			hosts[string(hostname)] = struct{}{}
+		}
+	}
+	return maps.Keys(hosts)
+}
+
+// getParentGateways returns the existing gateways referenced by given parent references (in the order of the references),
+// skipping gateways whose class does not match the given class filter
+func getParentGateways(ctx context.Context, c client.Client, namespace string, parentRefs []gatewayv1.ParentReference, classes ClassFilter) ([]*gatewayv1.Gateway, error) {
+	var gateways []*gatewayv1.Gateway
+	for _, parentRef := range parentRefs {
+		key, ok := getParentGatewayKey(namespace, parentRef)
+		if !ok {
+			continue
+		}
+		gateway := &gatewayv1.Gateway{}
+		if err := c.Get(ctx, key, gateway); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return nil, errors.Wrapf(err, "failed to get parent gateway %s", key)
+			}
+			continue
+		}
+		if _, ok := classes.Match(string(gateway.Spec.GatewayClassName)); !ok {
+			continue
+		}
+		gateways = append(gateways, gateway)
+	}
+	return gateways, nil
+}
+
+// getRouteTarget determines the default target of a route from the first parent gateway yielding a target;
+// that is, the gateway's masquerade-to annotation (if set), or the default target of its class, or its in-cluster address
+func getRouteTarget(ctx context.Context, c client.Client, gateways []*gatewayv1.Gateway, classes ClassFilter, clusterDomain string) (string, error) {
+	for _, gateway := range gateways {
+		if to := gateway.Annotations[annotationMasqueradeTo]; to != "" {
+			return to, nil
+		}
+		if to, _ := classes.Match(string(gateway.Spec.GatewayClassName)); to != "" {
+			return to, nil
+		}
+		to, err := getKubernetesGatewayTarget(ctx, c, gateway, clusterDomain)
+		if err != nil {
+			return "", err
+		}
+		if to != "" {
+			return to, nil
+		}
+	}
+	return "", nil
+}
+
+// getParentGatewayKey returns the key of the referenced gateway, if the given parent reference refers to a gateway
+func getParentGatewayKey(namespace string, parentRef gatewayv1.ParentReference) (types.NamespacedName, bool) {
+	if parentRef.Group != nil && *parentRef.Group != gatewayv1.GroupName {
+		return types.NamespacedName{}, false
+	}
+	if parentRef.Kind != nil && *parentRef.Kind != "Gateway" {
+		return types.NamespacedName{}, false
+	}
+	if parentRef.Namespace != nil && *parentRef.Namespace != "" {
+		namespace = string(*parentRef.Namespace)
+	}
+	return types.NamespacedName{Namespace: namespace, Name: string(parentRef.Name)}, true
+}
+
+// index function for routeParentGatewayIndex
+func indexRouteParentGateways(obj client.Object) []string {
+	_, parentRefs, err := getRouteSpec(obj)
+	if err != nil {
+		return nil
+	}
+	var keys []string
+	for _, parentRef := range parentRefs {
+		if key, ok := getParentGatewayKey(obj.GetNamespace(), parentRef); ok {
+			keys = append(keys, key.String())
+		}
+	}
+	return keys
+}
+
+// getRoutesForGateway returns the routes (of the type of given list) referencing given gateway as parent
+func getRoutesForGateway(ctx context.Context, c client.Client, routeList client.ObjectList, gateway client.Object) ([]client.Object, error) {
+	key := types.NamespacedName{Namespace: gateway.GetNamespace(), Name: gateway.GetName()}
+	if err := c.List(ctx, routeList, client.MatchingFields{routeParentGatewayIndex: key.String()}); err != nil {
+		return nil, errors.Wrap(err, "failed to list routes")
+	}
+	items, err := meta.ExtractList(routeList)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to extract routes")
+	}
+	routes := make([]client.Object, len(items))
+	for i, item := range items {
+		routes[i] = item.(client.Object)
+	}
+	return routes, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RouteReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	routeListGVK, err := mgr.GetClient().GroupVersionKindFor(r.Route)
+	if err != nil {
+		return err
+	}
+	routeListGVK.Kind += "List"
+
+	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), r.Route, routeParentGatewayIndex, indexRouteParentGateways); err != nil {
+		return errors.Wrapf(err, "failed to register field index %s", routeParentGatewayIndex)
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(r.Route, builder.WithPredicates(r.Filter.Predicate(), masqueradingStatusPredicate())).
+		Owns(&dnsv1alpha1.MasqueradingRule{}, builder.WithPredicates(masqueradingRulePredicate())).
+		Watches(
+			&gatewayv1.Gateway{},
+			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+				log := ctrl.LoggerFrom(ctx)
+				routeList, err := r.Scheme.New(routeListGVK)
+				if err != nil {
+					log.Error(err, "failed to create route list")
+					return nil
+				}
+				routes, err := getRoutesForGateway(ctx, r.Client, routeList.(client.ObjectList), obj)
+				if err != nil {
+					log.Error(err, "failed to list routes")
+					return nil
+				}
+				var requests []reconcile.Request
+				for _, route := range routes {
+					requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: route.GetNamespace(), Name: route.GetName()}})
+				}
+				return requests
+			}),
+		).
+		Complete(r)
+}
@@ -0,0 +1,265 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	istioscheme "istio.io/client-go/pkg/clientset/versioned/scheme"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	dnsv1alpha1 "github.com/sap/dns-masquerading-operator/api/v1alpha1"
+)
+
+func newFakeClientBuilder(t *testing.T) *fake.ClientBuilder {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("error building scheme: %s", err)
+	}
+	if err := gatewayv1.Install(scheme); err != nil {
+		t.Fatalf("error building scheme: %s", err)
+	}
+	if err := dnsv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("error building scheme: %s", err)
+	}
+	if err := istioscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("error building scheme: %s", err)
+	}
+	return fake.NewClientBuilder().WithScheme(scheme)
+}
+
+func newFakeClient(t *testing.T, objects ...client.Object) client.Client {
+	t.Helper()
+	return newFakeClientBuilder(t).WithObjects(objects...).Build()
+}
+
+func ref[T any](v T) *T {
+	return &v
+}
+
+func sorted(s []string) []string {
+	sort.Strings(s)
+	return s
+}
+
+func TestGetHostsFromKubernetesGateway(t *testing.T) {
+	gateway := &gatewayv1.Gateway{
+		Spec: gatewayv1.GatewaySpec{
+			Listeners: []gatewayv1.Listener{
+				{Name: "a", Hostname: ref(gatewayv1.Hostname("www.example.io"))},
+				{Name: "b", Hostname: ref(gatewayv1.Hostname("*.example.io"))},
+				{Name: "c", Hostname: ref(gatewayv1.Hostname("www.example.io"))},
+				{Name: "d", Hostname: ref(gatewayv1.Hostname(""))},
+				{Name: "e"},
+			},
+		},
+	}
+	expected := []string{"*.example.io", "www.example.io"}
+	if hosts := sorted(getHostsFromKubernetesGateway(gateway)); !reflect.DeepEqual(hosts, expected) {
+		t.Errorf("got hosts %v, want %v", hosts, expected)
+	}
+}
+
+func TestGetRouteSpec(t *testing.T) {
+	hostnames := []gatewayv1.Hostname{"www.example.io", "", "www.example.io", "api.example.io"}
+	parentRefs := []gatewayv1.ParentReference{{Name: "gateway"}}
+
+	tests := []struct {
+		name  string
+		route client.Object
+		err   bool
+	}{
+		{name: "http route", route: &gatewayv1.HTTPRoute{Spec: gatewayv1.HTTPRouteSpec{Hostnames: hostnames, CommonRouteSpec: gatewayv1.CommonRouteSpec{ParentRefs: parentRefs}}}},
+		{name: "grpc route", route: &gatewayv1.GRPCRoute{Spec: gatewayv1.GRPCRouteSpec{Hostnames: hostnames, CommonRouteSpec: gatewayv1.CommonRouteSpec{ParentRefs: parentRefs}}}},
+		{name: "tls route", route: &gatewayv1.TLSRoute{Spec: gatewayv1.TLSRouteSpec{Hostnames: hostnames, CommonRouteSpec: gatewayv1.CommonRouteSpec{ParentRefs: parentRefs}}}},
+		{name: "unsupported type", route: &corev1.Service{}, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, p, err := getRouteSpec(tt.route)
+			if tt.err {
+				if err == nil {
+					t.Errorf("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(h, hostnames) || !reflect.DeepEqual(p, parentRefs) {
+				t.Errorf("got hostnames %v and parent references %v, want %v and %v", h, p, hostnames, parentRefs)
+			}
+			expected := []string{"api.example.io", "www.example.io"}
+			if hosts := sorted(getHostsFromRoute(h)); !reflect.DeepEqual(hosts, expected) {
+				t.Errorf("got hosts %v, want %v", hosts, expected)
+			}
+		})
+	}
+}
+
+func TestGetParentGatewayKey(t *testing.T) {
+	tests := []struct {
+		name      string
+		parentRef gatewayv1.ParentReference
+		key       types.NamespacedName
+		ok        bool
+	}{
+		{name: "defaults", parentRef: gatewayv1.ParentReference{Name: "gateway"}, key: types.NamespacedName{Namespace: "routes", Name: "gateway"}, ok: true},
+		{name: "explicit group and kind", parentRef: gatewayv1.ParentReference{Group: ref(gatewayv1.Group(gatewayv1.GroupName)), Kind: ref(gatewayv1.Kind("Gateway")), Name: "gateway"}, key: types.NamespacedName{Namespace: "routes", Name: "gateway"}, ok: true},
+		{name: "explicit namespace", parentRef: gatewayv1.ParentReference{Namespace: ref(gatewayv1.Namespace("gateways")), Name: "gateway"}, key: types.NamespacedName{Namespace: "gateways", Name: "gateway"}, ok: true},
+		{name: "empty namespace", parentRef: gatewayv1.ParentReference{Namespace: ref(gatewayv1.Namespace("")), Name: "gateway"}, key: types.NamespacedName{Namespace: "routes", Name: "gateway"}, ok: true},
+		{name: "other kind", parentRef: gatewayv1.ParentReference{Group: ref(gatewayv1.Group("")), Kind: ref(gatewayv1.Kind("Service")), Name: "service"}},
+		{name: "other group", parentRef: gatewayv1.ParentReference{Group: ref(gatewayv1.Group("example.io")), Kind: ref(gatewayv1.Kind("Gateway")), Name: "gateway"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, ok := getParentGatewayKey("routes", tt.parentRef)
+			if ok != tt.ok || key != tt.key {
+				t.Errorf("got %v, %t, want %v, %t", key, ok, tt.key, tt.ok)
+			}
+		})
+	}
+}
+
+func TestGetRouteTarget(t *testing.T) {
+	newGateway := func(name string, class string, annotations map[string]string, addresses ...gatewayv1.GatewayStatusAddress) *gatewayv1.Gateway {
+		return &gatewayv1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "gateways", Name: name, Annotations: annotations},
+			Spec:       gatewayv1.GatewaySpec{GatewayClassName: gatewayv1.ObjectName(class)},
+			Status:     gatewayv1.GatewayStatus{Addresses: addresses},
+		}
+	}
+	annotated := newGateway("annotated", "istio", map[string]string{annotationMasqueradeTo: "annotated.example.io"})
+	classDefault := newGateway("class-default", "internal", nil)
+	withService := newGateway("with-service", "istio", nil)
+	withAddress := newGateway("with-address", "istio", nil,
+		gatewayv1.GatewayStatusAddress{Type: ref(gatewayv1.IPAddressType), Value: "10.0.0.1"},
+		gatewayv1.GatewayStatusAddress{Type: ref(gatewayv1.HostnameAddressType), Value: "lb.example.io"},
+	)
+	withoutTarget := newGateway("without-target", "istio", nil)
+	services := []client.Object{
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "gateways", Name: "with-service-istio", Labels: map[string]string{gatewayv1.GatewayNameLabelKey: "with-service"}}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "gateways", Name: "with-service-b", Labels: map[string]string{gatewayv1.GatewayNameLabelKey: "with-service"}}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "with-address", Labels: map[string]string{gatewayv1.GatewayNameLabelKey: "with-address"}}},
+	}
+	c := newFakeClient(t, services...)
+	classes := ClassFilter{"istio": "", "internal": "internal-gateway.istio-system.svc.cluster.local"}
+
+	tests := []struct {
+		name     string
+		gateways []*gatewayv1.Gateway
+		target   string
+	}{
+		{name: "annotation of gateway", gateways: []*gatewayv1.Gateway{annotated, classDefault}, target: "annotated.example.io"},
+		{name: "default target of class", gateways: []*gatewayv1.Gateway{classDefault, annotated}, target: "internal-gateway.istio-system.svc.cluster.local"},
+		{name: "gateway service (first by name)", gateways: []*gatewayv1.Gateway{withService}, target: "with-service-b.gateways.svc.cluster.local"},
+		{name: "hostname address of gateway", gateways: []*gatewayv1.Gateway{withAddress}, target: "lb.example.io"},
+		{name: "first gateway yielding a target", gateways: []*gatewayv1.Gateway{withoutTarget, withAddress}, target: "lb.example.io"},
+		{name: "no target", gateways: []*gatewayv1.Gateway{withoutTarget}, target: ""},
+		{name: "no gateways", target: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := getRouteTarget(context.Background(), c, tt.gateways, classes, "cluster.local")
+			if err != nil {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+			if target != tt.target {
+				t.Errorf("got target %q, want %q", target, tt.target)
+			}
+		})
+	}
+}
+
+func TestGetParentGateways(t *testing.T) {
+	c := newFakeClient(t,
+		&gatewayv1.Gateway{ObjectMeta: metav1.ObjectMeta{Namespace: "routes", Name: "a"}, Spec: gatewayv1.GatewaySpec{GatewayClassName: "istio"}},
+		&gatewayv1.Gateway{ObjectMeta: metav1.ObjectMeta{Namespace: "gateways", Name: "b"}, Spec: gatewayv1.GatewaySpec{GatewayClassName: "other"}},
+		&gatewayv1.Gateway{ObjectMeta: metav1.ObjectMeta{Namespace: "gateways", Name: "c"}, Spec: gatewayv1.GatewaySpec{GatewayClassName: "istio"}},
+	)
+	parentRefs := []gatewayv1.ParentReference{
+		{Namespace: ref(gatewayv1.Namespace("gateways")), Name: "c"},
+		{Name: "missing"},
+		{Kind: ref(gatewayv1.Kind("Service")), Name: "a"},
+		{Namespace: ref(gatewayv1.Namespace("gateways")), Name: "b"},
+		{Name: "a"},
+	}
+
+	tests := []struct {
+		name     string
+		classes  ClassFilter
+		gateways []string
+	}{
+		{name: "no class filter", gateways: []string{"gateways/c", "gateways/b", "routes/a"}},
+		{name: "class filter", classes: ClassFilter{"istio": ""}, gateways: []string{"gateways/c", "routes/a"}},
+		{name: "class filter without match", classes: ClassFilter{"nginx": ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateways, err := getParentGateways(context.Background(), c, "routes", parentRefs, tt.classes)
+			if err != nil {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+			var keys []string
+			for _, gateway := range gateways {
+				keys = append(keys, gateway.Namespace+"/"+gateway.Name)
+			}
+			if !reflect.DeepEqual(keys, tt.gateways) {
+				t.Errorf("got gateways %v, want %v", keys, tt.gateways)
+			}
+		})
+	}
+}
+
+func TestGetRoutesForGateway(t *testing.T) {
+	c := newFakeClientBuilder(t).
+		WithObjects(
+			&gatewayv1.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Namespace: "routes", Name: "local"}, Spec: gatewayv1.HTTPRouteSpec{CommonRouteSpec: gatewayv1.CommonRouteSpec{ParentRefs: []gatewayv1.ParentReference{{Name: "a"}}}}},
+			&gatewayv1.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Namespace: "routes", Name: "remote"}, Spec: gatewayv1.HTTPRouteSpec{CommonRouteSpec: gatewayv1.CommonRouteSpec{ParentRefs: []gatewayv1.ParentReference{
+				{Name: "a", Kind: ref(gatewayv1.Kind("Service"))},
+				{Name: "a", Namespace: ref(gatewayv1.Namespace("gateways"))},
+			}}}},
+			&gatewayv1.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "local"}, Spec: gatewayv1.HTTPRouteSpec{CommonRouteSpec: gatewayv1.CommonRouteSpec{ParentRefs: []gatewayv1.ParentReference{{Name: "a"}}}}},
+		).
+		WithIndex(&gatewayv1.HTTPRoute{}, routeParentGatewayIndex, indexRouteParentGateways).
+		Build()
+
+	tests := []struct {
+		name    string
+		gateway *gatewayv1.Gateway
+		routes  []string
+	}{
+		{name: "routes in same namespace", gateway: &gatewayv1.Gateway{ObjectMeta: metav1.ObjectMeta{Namespace: "routes", Name: "a"}}, routes: []string{"routes/local"}},
+		{name: "routes in other namespace", gateway: &gatewayv1.Gateway{ObjectMeta: metav1.ObjectMeta{Namespace: "gateways", Name: "a"}}, routes: []string{"routes/remote"}},
+		{name: "no routes", gateway: &gatewayv1.Gateway{ObjectMeta: metav1.ObjectMeta{Namespace: "gateways", Name: "b"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routes, err := getRoutesForGateway(context.Background(), c, &gatewayv1.HTTPRouteList{}, tt.gateway)
+			if err != nil {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+			var keys []string
+			for _, route := range routes {
+				keys = append(keys, route.GetNamespace()+"/"+route.GetName())
+			}
+			if keys := sorted(keys); !reflect.DeepEqual(keys, tt.routes) {
+				t.Errorf("got routes %v, want %v", keys, tt.routes)
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sap/go-generics/maps"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/jsonpath"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	dnsv1alpha1 "github.com/sap/dns-masquerading-operator/api/v1alpha1"
+)
+
+// GenericSourcesConfig describes additional (arbitrary) resource types serving as sources for masquerading rules
+type GenericSourcesConfig struct {
+	Sources []GenericSource `json:"sources"`
+}
+
+// GenericSource describes a resource type serving as source for masquerading rules
+type GenericSource struct {
+	// API group of the resource type (empty for the core group)
+	Group string `json:"group,omitempty"`
+	// API version of the resource type
+	Version string `json:"version"`
+	// Kind of the resource type
+	Kind string `json:"kind"`
+	// JSONPath expressions (e.g. '.spec.host', or '{.spec.rules[*].host}') yielding the hosts to be masqueraded;
+	// values which are URLs (such as https://my.example.io) are reduced to their hostname
+	Hosts []string `json:"hosts"`
+	// Optional JSONPath expression yielding the default rewrite target (used if the masquerade-to annotation is missing);
+	// values which are URLs are reduced to their hostname
+	Target string `json:"target,omitempty"`
+}
+
+// Return group version kind of a generic source
+func (s *GenericSource) GroupVersionKind() schema.GroupVersionKind {
+	return schema.GroupVersionKind{Group: s.Group, Version: s.Version, Kind: s.Kind}
+}
+
+// LoadGenericSourcesConfig reads and validates generic sources configuration from the given (yaml or json) file
+func LoadGenericSourcesConfig(path string) (*GenericSourcesConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading generic sources config %s", path)
+	}
+	config := &GenericSourcesConfig{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, errors.Wrapf(err, "error parsing generic sources config %s", path)
+	}
+	gvks := make(map[schema.GroupVersionKind]struct{})
+	for _, source := range config.Sources {
+		gvk := source.GroupVersionKind()
+		if source.Version == "" || source.Kind == "" {
+			return nil, fmt.Errorf("error validating generic sources config %s: version and kind must be specified (%s)", path, gvk)
+		}
+		if _, ok := gvks[gvk]; ok {
+			return nil, fmt.Errorf("error validating generic sources config %s: duplicate source %s", path, gvk)
+		}
+		gvks[gvk] = struct{}{}
+		if len(source.Hosts) == 0 {
+			return nil, fmt.Errorf("error validating generic sources config %s: no hosts specified for %s", path, gvk)
+		}
+		for _, expression := range append(source.Hosts, source.Target) {
+			if expression == "" {
+				continue
+			}
+			if _, err := parseJsonPath(expression); err != nil {
+				return nil, errors.Wrapf(err, "error validating generic sources config %s (%s)", path, gvk)
+			}
+		}
+	}
+	return config, nil
+}
+
+// GenericReconciler reconciles objects of an arbitrary resource type, as described by a GenericSource
+type GenericReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	Source   GenericSource
+	hosts    []*jsonpath.JSONPath
+	target   *jsonpath.JSONPath
+}
+
+// Reconcile an arbitrary resource
+func (r *GenericReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	log := ctrl.LoggerFrom(ctx)
+	log.V(1).Info("running reconcile")
+
+	// Retrieve target object
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(r.Source.GroupVersionKind())
+	if err := r.Get(ctx, req.NamespacedName, obj); err != nil {
+		if err := client.IgnoreNotFound(err); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "unexpected get error")
+		}
+		log.Info("not found; ignoring")
+		return ctrl.Result{}, nil
+	}
+
+	hosts, err := r.getHostsFromObject(obj)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	defaultTo := ""
+	if r.target != nil && autoTargetEnabled(obj) {
+		targets, err := evaluateJsonPath(r.target, obj)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if len(targets) > 0 {
+			defaultTo = targets[0]
+		}
+	}
+
+	if err := manageDependents(ctx, r.Client, r.Recorder, obj, getHosts(ctx, obj, hosts...), defaultTo); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// extract hosts of an arbitrary resource by evaluating the configured JSONPath expressions
+func (r *GenericReconciler) getHostsFromObject(obj *unstructured.Unstructured) ([]string, error) {
+	hosts := make(map[string]struct{})
+	for _, j := range r.hosts {
+		values, err := evaluateJsonPath(j, obj)
+		if err != nil {
+			return nil, err
+		}
+		for _, host := range values {
+			hosts[host] = struct{}{}
+		}
+	}
+	return maps.Keys(hosts), nil
+}
+
+// parse JSONPath expression; the expression may be specified with or without enclosing braces
+func parseJsonPath(expression string) (*jsonpath.JSONPath, error) {
+	if !strings.Contains(expression, "{") {
+		expression = "{" + expression + "}"
+	}
+	j := jsonpath.New("").AllowMissingKeys(true)
+	if err := j.Parse(expression); err != nil {
+		return nil, errors.Wrapf(err, "error parsing jsonpath expression %s", expression)
+	}
+	return j, nil
+}
+
+// evaluate JSONPath expression against an arbitrary resource; returns the non-empty string values found,
+// where values which are URLs are reduced to their hostname
+func evaluateJsonPath(j *jsonpath.JSONPath, obj *unstructured.Unstructured) ([]string, error) {
+	results, err := j.FindResults(obj.Object)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error evaluating jsonpath expression against %s %s/%s", obj.GetKind(), obj.GetNamespace(), obj.GetName())
+	}
+	var values []string
+	for _, result := range results {
+		for _, value := range result {
+			var items []any
+			switch v := value.Interface().(type) {
+			case []any:
+				items = v
+			default:
+				items = []any{v}
+			}
+			for _, item := range items {
+				s, ok := item.(string)
+				if !ok {
+					continue
+				}
+				s = strings.TrimSpace(s)
+				if strings.Contains(s, "://") {
+					u, err := url.Parse(s)
+					if err != nil {
+						continue
+					}
+					s = u.Hostname()
+				}
+				if s != "" {
+					values = append(values, s)
+				}
+			}
+		}
+	}
+	return values, nil
+}
+
+// create empty object of the given type; types not known to the scheme (such as generic sources) are returned as unstructured objects
+func newObjectForGroupVersionKind(scheme *runtime.Scheme, gvk schema.GroupVersionKind) (client.Object, error) {
+	obj, err := scheme.New(gvk)
+	if runtime.IsNotRegisteredError(err) {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+		return obj, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return obj.(client.Object), nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GenericReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.hosts = nil
+	for _, expression := range r.Source.Hosts {
+		j, err := parseJsonPath(expression)
+		if err != nil {
+			return err
+		}
+		r.hosts = append(r.hosts, j)
+	}
+	r.target = nil
+	if r.Source.Target != "" {
+		j, err := parseJsonPath(r.Source.Target)
+		if err != nil {
+			return err
+		}
+		r.target = j
+	}
+
+	gvk := r.Source.GroupVersionKind()
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	name := "generic-" + strings.ToLower(gvk.Kind)
+	if gvk.Group != "" {
+		name += "." + gvk.Group
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(obj, builder.WithPredicates(masqueradingStatusPredicate())).
+		Owns(&dnsv1alpha1.MasqueradingRule{}, builder.WithPredicates(masqueradingRulePredicate())).
+		Complete(r)
+}
@@ -0,0 +1,223 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controllers
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestLoadGenericSourcesConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		sources []GenericSource
+		err     bool
+	}{
+		{
+			name: "valid yaml",
+			content: `
+sources:
+- group: serving.knative.dev
+  version: v1
+  kind: Service
+  hosts:
+  - .status.url
+  - '{.spec.rules[*].host}'
+  target: .status.address.url
+- version: v1
+  kind: ConfigMap
+  hosts:
+  - .data.host
+`,
+			sources: []GenericSource{
+				{Group: "serving.knative.dev", Version: "v1", Kind: "Service", Hosts: []string{".status.url", "{.spec.rules[*].host}"}, Target: ".status.address.url"},
+				{Version: "v1", Kind: "ConfigMap", Hosts: []string{".data.host"}},
+			},
+		},
+		{
+			name:    "valid json",
+			content: `{"sources": [{"group": "projectcontour.io", "version": "v1", "kind": "HTTPProxy", "hosts": [".spec.virtualhost.fqdn"]}]}`,
+			sources: []GenericSource{{Group: "projectcontour.io", Version: "v1", Kind: "HTTPProxy", Hosts: []string{".spec.virtualhost.fqdn"}}},
+		},
+		{
+			name:    "empty",
+			content: `sources: []`,
+			sources: []GenericSource{},
+		},
+		{
+			name:    "unknown field",
+			content: "sources:\n- version: v1\n  kind: Service\n  hosts: [.spec.host]\n  hostz: [.spec.host]\n",
+			err:     true,
+		},
+		{
+			name:    "missing kind",
+			content: "sources:\n- version: v1\n  hosts: [.spec.host]\n",
+			err:     true,
+		},
+		{
+			name:    "missing version",
+			content: "sources:\n- kind: Service\n  hosts: [.spec.host]\n",
+			err:     true,
+		},
+		{
+			name:    "duplicate source",
+			content: "sources:\n- version: v1\n  kind: Service\n  hosts: [.spec.host]\n- version: v1\n  kind: Service\n  hosts: [.spec.other]\n",
+			err:     true,
+		},
+		{
+			name:    "no hosts",
+			content: "sources:\n- version: v1\n  kind: Service\n",
+			err:     true,
+		},
+		{
+			name:    "invalid hosts expression",
+			content: "sources:\n- version: v1\n  kind: Service\n  hosts: ['{.spec.rules[*.host}']\n",
+			err:     true,
+		},
+		{
+			name:    "invalid target expression",
+			content: "sources:\n- version: v1\n  kind: Service\n  hosts: [.spec.host]\n  target: '{.status.address'\n",
+			err:     true,
+		},
+		{
+			name:    "invalid yaml",
+			content: "sources: [",
+			err:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("error writing config: %s", err)
+			}
+			config, err := LoadGenericSourcesConfig(path)
+			if tt.err {
+				if err == nil {
+					t.Errorf("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(config.Sources, tt.sources) {
+				t.Errorf("got sources %+v, want %+v", config.Sources, tt.sources)
+			}
+		})
+	}
+
+	if _, err := LoadGenericSourcesConfig(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Errorf("expected error for missing file, got none")
+	}
+}
+
+func TestEvaluateJsonPath(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "example.io/v1",
+		"kind":       "Route",
+		"metadata":   map[string]any{"namespace": "default", "name": "test"},
+		"spec": map[string]any{
+			"host":    "www.example.io",
+			"aliases": []any{"a.example.io", " b.example.io ", "", 42, "https://c.example.io:8443/path"},
+			"rules": []any{
+				map[string]any{"host": "r1.example.io"},
+				map[string]any{"path": "/"},
+				map[string]any{"host": "r2.example.io"},
+			},
+			"port": 8080,
+		},
+		"status": map[string]any{
+			"url": "https://app.example.io/",
+		},
+	}}
+
+	tests := []struct {
+		name       string
+		expression string
+		values     []string
+	}{
+		{name: "single value without braces", expression: ".spec.host", values: []string{"www.example.io"}},
+		{name: "single value with braces", expression: "{.spec.host}", values: []string{"www.example.io"}},
+		{name: "list value", expression: ".spec.aliases", values: []string{"a.example.io", "b.example.io", "c.example.io"}},
+		{name: "wildcard over list", expression: "{.spec.rules[*].host}", values: []string{"r1.example.io", "r2.example.io"}},
+		{name: "url reduced to hostname", expression: ".status.url", values: []string{"app.example.io"}},
+		{name: "missing path", expression: ".spec.missing", values: nil},
+		{name: "missing nested path", expression: ".status.address.url", values: nil},
+		{name: "non-string value", expression: ".spec.port", values: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j, err := parseJsonPath(tt.expression)
+			if err != nil {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+			values, err := evaluateJsonPath(j, obj)
+			if err != nil {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(values, tt.values) {
+				t.Errorf("got values %q, want %q", values, tt.values)
+			}
+		})
+	}
+}
+
+func TestGetHostsFromObject(t *testing.T) {
+	r := &GenericReconciler{}
+	for _, expression := range []string{".spec.host", "{.spec.rules[*].host}"} {
+		j, err := parseJsonPath(expression)
+		if err != nil {
+			t.Fatalf("got unexpected error: %s", err)
+		}
+		r.hosts = append(r.hosts, j)
+	}
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{
+			"host": "www.example.io",
+			"rules": []any{
+				map[string]any{"host": "www.example.io"},
+				map[string]any{"host": "api.example.io"},
+			},
+		},
+	}}
+
+	hosts, err := r.getHostsFromObject(obj)
+	if err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	expected := []string{"api.example.io", "www.example.io"}
+	if hosts := sorted(hosts); !reflect.DeepEqual(hosts, expected) {
+		t.Errorf("got hosts %v, want %v", hosts, expected)
+	}
+}
+
+func TestNewObjectForGroupVersionKind(t *testing.T) {
+	obj, err := newObjectForGroupVersionKind(clientgoscheme.Scheme, corev1.SchemeGroupVersion.WithKind("Service"))
+	if err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	if _, ok := obj.(*corev1.Service); !ok {
+		t.Errorf("got %T, want %T", obj, &corev1.Service{})
+	}
+
+	gvk := schema.GroupVersionKind{Group: "serving.knative.dev", Version: "v1", Kind: "Service"}
+	obj, err = newObjectForGroupVersionKind(clientgoscheme.Scheme, gvk)
+	if err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	if u, ok := obj.(*unstructured.Unstructured); !ok || u.GroupVersionKind() != gvk {
+		t.Errorf("got %T (%v), want unstructured object of type %s", obj, obj.GetObjectKind().GroupVersionKind(), gvk)
+	}
+}
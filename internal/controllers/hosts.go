@@ -0,0 +1,82 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/sap/go-generics/maps"
+	"github.com/sap/go-generics/slices"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/sap/dns-masquerading-operator/internal/coredns"
+	"github.com/sap/dns-masquerading-operator/internal/dnsname"
+)
+
+const (
+	annotationMasqueradeExclude   = "dns.cs.sap.com/masquerade-exclude"
+	annotationExternalDnsHostname = "external-dns.alpha.kubernetes.io/hostname"
+	annotationGardenerDnsNames    = "dns.gardener.cloud/dnsnames"
+)
+
+// getHosts determines the hosts to be masqueraded for an arbitrary source resource; that is, the given hosts (usually derived from the
+// resource's spec), plus the hosts listed in the masquerade-from, external-dns and gardener dns annotations, minus the hosts listed
+// in the masquerade-exclude annotation; all hosts are trimmed and lowercased; the catch-all host '*' and invalid hosts are dropped
+func getHosts(ctx context.Context, obj client.Object, hosts ...string) []string {
+	log := ctrl.LoggerFrom(ctx)
+
+	validHosts, invalidHosts := checkHosts(obj, hosts...)
+	for _, host := range invalidHosts {
+		log.Info("ignoring invalid host", "host", host)
+	}
+	return validHosts
+}
+
+// determine the hosts of an arbitrary source resource (see getHosts), returning valid and invalid hosts separately
+func checkHosts(obj client.Object, hosts ...string) ([]string, []string) {
+	candidates := append([]string{}, hosts...)
+	for _, key := range []string{annotationMasqueradeFrom, annotationExternalDnsHostname, annotationGardenerDnsNames} {
+		candidates = append(candidates, splitHosts(obj.GetAnnotations()[key])...)
+	}
+	excluded := make(map[string]struct{})
+	for _, host := range splitHosts(obj.GetAnnotations()[annotationMasqueradeExclude]) {
+		excluded[normalizeHost(host)] = struct{}{}
+	}
+
+	validHosts := make(map[string]struct{})
+	invalidHosts := make(map[string]struct{})
+	for _, host := range candidates {
+		host = normalizeHost(host)
+		if host == "" || host == "*" {
+			continue
+		}
+		if _, ok := excluded[host]; ok {
+			continue
+		}
+		if err := dnsname.Validate(host, coredns.SourceNameOptions); err != nil {
+			invalidHosts[host] = struct{}{}
+			continue
+		}
+		validHosts[host] = struct{}{}
+	}
+	return maps.Keys(validHosts), slices.Sort(maps.Keys(invalidHosts))
+}
+
+// split comma-separated list of hosts
+func splitHosts(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// trim and lowercase host, and strip trailing dot
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
@@ -0,0 +1,97 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetHosts(t *testing.T) {
+	tests := []struct {
+		name        string
+		hosts       []string
+		annotations map[string]string
+		valid       []string
+		invalid     []string
+	}{
+		{
+			name:  "hosts from spec",
+			hosts: []string{"www.example.io", "api.example.io"},
+			valid: []string{"api.example.io", "www.example.io"},
+		},
+		{
+			name:        "comma-separated annotations",
+			hosts:       []string{"www.example.io"},
+			annotations: map[string]string{annotationMasqueradeFrom: "a.example.io, b.example.io,,", annotationExternalDnsHostname: "c.example.io,www.example.io", annotationGardenerDnsNames: " d.example.io "},
+			valid:       []string{"a.example.io", "b.example.io", "c.example.io", "d.example.io", "www.example.io"},
+		},
+		{
+			name:        "blank annotations",
+			annotations: map[string]string{annotationMasqueradeFrom: "  ", annotationExternalDnsHostname: ""},
+			valid:       []string{},
+		},
+		{
+			name:  "normalization",
+			hosts: []string{" WWW.Example.io. ", "www.example.io", "*.Example.io"},
+			valid: []string{"*.example.io", "www.example.io"},
+		},
+		{
+			name:        "exclude annotation",
+			hosts:       []string{"www.example.io", "api.example.io", "*.example.io"},
+			annotations: map[string]string{annotationMasqueradeFrom: "a.example.io", annotationMasqueradeExclude: "API.example.io., a.example.io,*.example.io"},
+			valid:       []string{"www.example.io"},
+		},
+		{
+			name:        "exclude annotation with unknown hosts",
+			hosts:       []string{"www.example.io"},
+			annotations: map[string]string{annotationMasqueradeExclude: "other.example.io"},
+			valid:       []string{"www.example.io"},
+		},
+		{
+			name:  "catch-all and empty hosts",
+			hosts: []string{"*", "", " ", "www.example.io"},
+			valid: []string{"www.example.io"},
+		},
+		{
+			name:        "invalid hosts",
+			hosts:       []string{"www.example.io", "in valid.example.io", "www.*.example.io", "-leading.example.io", "example.123"},
+			annotations: map[string]string{annotationMasqueradeFrom: "_service.example.io,under_score.example.io,bad..example.io"},
+			valid:       []string{"_service.example.io", "www.example.io"},
+			invalid:     []string{"-leading.example.io", "bad..example.io", "example.123", "in valid.example.io", "under_score.example.io", "www.*.example.io"},
+		},
+		{
+			name:        "excluded invalid hosts are not reported",
+			hosts:       []string{"in valid.example.io"},
+			annotations: map[string]string{annotationMasqueradeExclude: "in valid.example.io"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test", Annotations: tt.annotations}}
+			valid, invalid := checkHosts(obj, tt.hosts...)
+			if valid := sorted(valid); len(valid) > 0 || len(tt.valid) > 0 {
+				if !reflect.DeepEqual(valid, tt.valid) {
+					t.Errorf("got valid hosts %q, want %q", valid, tt.valid)
+				}
+			}
+			if len(invalid) > 0 || len(tt.invalid) > 0 {
+				if !reflect.DeepEqual(invalid, tt.invalid) {
+					t.Errorf("got invalid hosts %q, want %q", invalid, tt.invalid)
+				}
+			}
+			if hosts := sorted(getHosts(context.Background(), obj, tt.hosts...)); len(hosts) > 0 || len(tt.valid) > 0 {
+				if !reflect.DeepEqual(hosts, tt.valid) {
+					t.Errorf("got hosts %q, want %q", hosts, tt.valid)
+				}
+			}
+		})
+	}
+}
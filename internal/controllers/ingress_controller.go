@@ -7,20 +7,45 @@ package controllers
 
 import (
 	"context"
+	"fmt"
+	"sort"
 
 	"github.com/pkg/errors"
 	"github.com/sap/go-generics/maps"
 
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	dnsv1alpha1 "github.com/sap/dns-masquerading-operator/api/v1alpha1"
+)
+
+const (
+	annotationIngressClassLegacy = "kubernetes.io/ingress.class"
+)
+
+const (
+	// field index of ingresses, holding the name of the referenced ingress class (empty if no class is referenced)
+	ingressClassNameIndex = "spec.ingressClassName"
+	// field index of load balancer services, holding the IP addresses and hostnames of their load balancer status
+	serviceLoadBalancerAddressIndex = "status.loadBalancer.ingress"
 )
 
 // IngressReconciler reconciles an Ingress object
 type IngressReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme        *runtime.Scheme
+	Recorder      record.EventRecorder
+	Filter        *SourceFilter
+	Classes       ClassFilter
+	ClusterDomain string
 }
 
 // Reconcile an ingress resource
@@ -38,16 +63,83 @@ func (r *IngressReconciler) Reconcile(ctx context.Context, req ctrl.Request) (re
 		return ctrl.Result{}, nil
 	}
 
-	if err := manageDependents(ctx, r.Client, ingress, getHostsFromIngress(ingress)); err != nil {
+	// Clean up masquerading rules of objects not matching the filters (any longer)
+	classTarget, matches, err := r.matchFilters(ctx, ingress)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !matches {
+		log.V(1).Info("not matching filter; ignoring")
+		if err := manageDependentsWithTargets(ctx, r.Client, ingress, nil); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	targets, err := r.getTargets(ctx, ingress, classTarget)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := manageDependentsWithTargets(ctx, r.Client, ingress, targets); err != nil {
 		return ctrl.Result{}, err
 	}
 
 	return ctrl.Result{}, nil
 }
 
+// GetTargets determines the masquerading rules (hosts mapped to rewrite targets) which would be maintained for given ingress;
+// in contrast to the reconciliation, invalid hosts and invalid entries of the masquerade-map annotation are not skipped, but returned as error
+func (r *IngressReconciler) GetTargets(ctx context.Context, obj client.Object) (map[string]string, error) {
+	ingress, ok := obj.(*networkingv1.Ingress)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type %T", obj)
+	}
+	classTarget, matches, err := r.matchFilters(ctx, ingress)
+	if err != nil || !matches {
+		return nil, err
+	}
+	if err := validateAnnotations(ingress, getHostsFromIngress(ingress)...); err != nil {
+		return nil, err
+	}
+	return r.getTargets(ctx, ingress, classTarget)
+}
+
+// check if an ingress matches the filters; if it matches the ingress class filter, the default target of the class is returned as well
+func (r *IngressReconciler) matchFilters(ctx context.Context, ingress *networkingv1.Ingress) (string, bool, error) {
+	if !r.Filter.Matches(ingress) {
+		return "", false, nil
+	}
+	if r.Classes == nil {
+		return "", true, nil
+	}
+	className, err := getIngressClassName(ctx, r.Client, ingress)
+	if err != nil {
+		return "", false, err
+	}
+	classTarget, matches := r.Classes.Match(className)
+	return classTarget, matches, nil
+}
+
+// determine masquerading rules (hosts mapped to rewrite targets) of an ingress; classTarget is the default target provided by the
+// ingress class filter (if any)
+func (r *IngressReconciler) getTargets(ctx context.Context, ingress *networkingv1.Ingress, classTarget string) (map[string]string, error) {
+	defaultTo := ""
+	if autoTargetEnabled(ingress) {
+		defaultTo = classTarget
+		if defaultTo == "" {
+			var err error
+			defaultTo, err = getIngressTarget(ctx, r.Client, ingress, r.ClusterDomain)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return getTargets(ctx, r.Client, r.Recorder, ingress, getHosts(ctx, ingress, getHostsFromIngress(ingress)...), defaultTo)
+}
+
 // getHostsFromIngress extracts hosts of an ingress resource
 func getHostsFromIngress(ingress *networkingv1.Ingress) []string {
-	// TODO: consider external-dns.alpha.kubernetes.io/hostname and dns.gardener.cloud/dnsnames annotation as well ?
 	hosts := make(map[string]struct{})
 	for _, rule := range ingress.Spec.Rules {
 		if rule.Host != "" {
@@ -57,9 +149,177 @@ func getHostsFromIngress(ingress *networkingv1.Ingress) []string {
 	return maps.Keys(hosts)
 }
 
+// getIngressTarget determines the default target of an ingress; that is, the masquerade-to annotation of its ingress class (if set),
+// or the in-cluster address of the ingress controller's load balancer service (identified by matching the load balancer status
+// of the ingress), or the first IP address or hostname reported in the ingress' load balancer status;
+// returns an empty string if nothing was found
+func getIngressTarget(ctx context.Context, c client.Client, ingress *networkingv1.Ingress, clusterDomain string) (string, error) {
+	ingressClass, err := getIngressClass(ctx, c, ingress)
+	if err != nil {
+		return "", err
+	}
+	if ingressClass != nil {
+		if to := ingressClass.Annotations[annotationMasqueradeTo]; to != "" {
+			return to, nil
+		}
+	}
+
+	if len(ingress.Status.LoadBalancer.Ingress) == 0 {
+		return "", nil
+	}
+
+	// note: only load balancer services are indexed, and the index holds the addresses of their load balancer status
+	var services []*corev1.Service
+	seen := make(map[types.NamespacedName]struct{})
+	for _, address := range getIngressLoadBalancerAddresses(ingress) {
+		serviceList := &corev1.ServiceList{}
+		if err := c.List(ctx, serviceList, client.MatchingFields{serviceLoadBalancerAddressIndex: address}); err != nil {
+			return "", errors.Wrap(err, "failed to list services")
+		}
+		for i := range serviceList.Items {
+			service := &serviceList.Items[i]
+			key := types.NamespacedName{Namespace: service.Namespace, Name: service.Name}
+			if _, ok := seen[key]; !ok {
+				seen[key] = struct{}{}
+				services = append(services, service)
+			}
+		}
+	}
+	if len(services) > 0 {
+		sort.Slice(services, func(i, j int) bool {
+			if services[i].Namespace != services[j].Namespace {
+				return services[i].Namespace < services[j].Namespace
+			}
+			return services[i].Name < services[j].Name
+		})
+		return getServiceAddress(services[0], clusterDomain), nil
+	}
+
+	for _, loadBalancerIngress := range ingress.Status.LoadBalancer.Ingress {
+		if loadBalancerIngress.IP != "" {
+			return loadBalancerIngress.IP, nil
+		}
+		if loadBalancerIngress.Hostname != "" {
+			return loadBalancerIngress.Hostname, nil
+		}
+	}
+	return "", nil
+}
+
+// getIngressClass returns the ingress class of an ingress; that is, the class referenced by spec.ingressClassName (or by the legacy
+// annotation), or the default ingress class, if the ingress does not reference any class; returns nil if no such class exists
+func getIngressClass(ctx context.Context, c client.Client, ingress *networkingv1.Ingress) (*networkingv1.IngressClass, error) {
+	if className := getReferencedIngressClassName(ingress); className != "" {
+		ingressClass := &networkingv1.IngressClass{}
+		if err := c.Get(ctx, types.NamespacedName{Name: className}, ingressClass); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return nil, errors.Wrapf(err, "failed to get ingress class %s", className)
+			}
+			return nil, nil
+		}
+		return ingressClass, nil
+	}
+
+	ingressClassList := &networkingv1.IngressClassList{}
+	if err := c.List(ctx, ingressClassList); err != nil {
+		return nil, errors.Wrap(err, "failed to list ingress classes")
+	}
+	for _, ingressClass := range ingressClassList.Items {
+		if ingressClass.Annotations[networkingv1.AnnotationIsDefaultIngressClass] == "true" {
+			return &ingressClass, nil
+		}
+	}
+	return nil, nil
+}
+
+// getIngressClassName returns the name of the ingress class of an ingress; that is, the class referenced by the ingress (even if it
+// does not exist), or the default ingress class; returns an empty string if there is none
+func getIngressClassName(ctx context.Context, c client.Client, ingress *networkingv1.Ingress) (string, error) {
+	if className := getReferencedIngressClassName(ingress); className != "" {
+		return className, nil
+	}
+	ingressClass, err := getIngressClass(ctx, c, ingress)
+	if err != nil || ingressClass == nil {
+		return "", err
+	}
+	return ingressClass.Name, nil
+}
+
+// getReferencedIngressClassName returns the ingress class referenced by spec.ingressClassName, or by the legacy annotation
+func getReferencedIngressClassName(ingress *networkingv1.Ingress) string {
+	if ingress.Spec.IngressClassName != nil {
+		return *ingress.Spec.IngressClassName
+	}
+	return ingress.Annotations[annotationIngressClassLegacy]
+}
+
+// return the IP addresses and hostnames of the load balancer status of given ingress
+func getIngressLoadBalancerAddresses(ingress *networkingv1.Ingress) []string {
+	var addresses []string
+	for _, loadBalancerIngress := range ingress.Status.LoadBalancer.Ingress {
+		if loadBalancerIngress.IP != "" {
+			addresses = append(addresses, loadBalancerIngress.IP)
+		}
+		if loadBalancerIngress.Hostname != "" {
+			addresses = append(addresses, loadBalancerIngress.Hostname)
+		}
+	}
+	return addresses
+}
+
+// index function for ingressClassNameIndex
+func indexIngressClassName(obj client.Object) []string {
+	return []string{getReferencedIngressClassName(obj.(*networkingv1.Ingress))}
+}
+
+// index function for serviceLoadBalancerAddressIndex
+func indexServiceLoadBalancerAddresses(obj client.Object) []string {
+	service := obj.(*corev1.Service)
+	if service.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		return nil
+	}
+	var addresses []string
+	for _, loadBalancerIngress := range service.Status.LoadBalancer.Ingress {
+		if loadBalancerIngress.IP != "" {
+			addresses = append(addresses, loadBalancerIngress.IP)
+		}
+		if loadBalancerIngress.Hostname != "" {
+			addresses = append(addresses, loadBalancerIngress.Hostname)
+		}
+	}
+	return addresses
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *IngressReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &networkingv1.Ingress{}, ingressClassNameIndex, indexIngressClassName); err != nil {
+		return errors.Wrapf(err, "failed to register field index %s", ingressClassNameIndex)
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &corev1.Service{}, serviceLoadBalancerAddressIndex, indexServiceLoadBalancerAddresses); err != nil {
+		return errors.Wrapf(err, "failed to register field index %s", serviceLoadBalancerAddressIndex)
+	}
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&networkingv1.Ingress{}).
+		For(&networkingv1.Ingress{}, builder.WithPredicates(r.Filter.Predicate(), masqueradingStatusPredicate())).
+		Owns(&dnsv1alpha1.MasqueradingRule{}, builder.WithPredicates(masqueradingRulePredicate())).
+		Watches(
+			&networkingv1.IngressClass{},
+			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+				// enqueue ingresses referencing the class, and ingresses referencing no class at all (since they are affected
+				// if the class becomes, or ceases to be, the default class)
+				log := ctrl.LoggerFrom(ctx)
+				var requests []reconcile.Request
+				for _, className := range []string{obj.GetName(), ""} {
+					ingressList := &networkingv1.IngressList{}
+					if err := r.List(ctx, ingressList, client.MatchingFields{ingressClassNameIndex: className}); err != nil {
+						log.Error(err, "failed to list ingresses")
+						return nil
+					}
+					for _, ingress := range ingressList.Items {
+						requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}})
+					}
+				}
+				return requests
+			}),
+		).
 		Complete(r)
 }
@@ -0,0 +1,178 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newIngressTestClient(t *testing.T, objects ...client.Object) client.Client {
+	t.Helper()
+	return newFakeClientBuilder(t).
+		WithObjects(objects...).
+		WithIndex(&networkingv1.Ingress{}, ingressClassNameIndex, indexIngressClassName).
+		WithIndex(&corev1.Service{}, serviceLoadBalancerAddressIndex, indexServiceLoadBalancerAddresses).
+		Build()
+}
+
+func newLoadBalancerService(namespace string, name string, serviceType corev1.ServiceType, ingress ...corev1.LoadBalancerIngress) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       corev1.ServiceSpec{Type: serviceType},
+		Status:     corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{Ingress: ingress}},
+	}
+}
+
+func newIngress(className string, annotations map[string]string, ingress ...networkingv1.IngressLoadBalancerIngress) *networkingv1.Ingress {
+	obj := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test", Annotations: annotations},
+		Status:     networkingv1.IngressStatus{LoadBalancer: networkingv1.IngressLoadBalancerStatus{Ingress: ingress}},
+	}
+	if className != "" {
+		obj.Spec.IngressClassName = &className
+	}
+	return obj
+}
+
+func TestGetIngressTarget(t *testing.T) {
+	objects := []client.Object{
+		&networkingv1.IngressClass{ObjectMeta: metav1.ObjectMeta{Name: "annotated", Annotations: map[string]string{annotationMasqueradeTo: "annotated.example.io"}}},
+		&networkingv1.IngressClass{ObjectMeta: metav1.ObjectMeta{Name: "nginx"}},
+		newLoadBalancerService("ingress-nginx", "controller", corev1.ServiceTypeLoadBalancer, corev1.LoadBalancerIngress{IP: "10.0.0.1"}),
+		newLoadBalancerService("ingress-nginx", "controller-b", corev1.ServiceTypeLoadBalancer, corev1.LoadBalancerIngress{IP: "10.0.0.1"}, corev1.LoadBalancerIngress{IP: "10.0.0.2"}),
+		newLoadBalancerService("ingress-alb", "controller", corev1.ServiceTypeLoadBalancer, corev1.LoadBalancerIngress{Hostname: "lb.example.io"}),
+		newLoadBalancerService("other", "cluster-ip", corev1.ServiceTypeClusterIP, corev1.LoadBalancerIngress{IP: "10.0.0.3"}),
+	}
+	defaultClass := &networkingv1.IngressClass{ObjectMeta: metav1.ObjectMeta{Name: "default", Annotations: map[string]string{
+		networkingv1.AnnotationIsDefaultIngressClass: "true",
+		annotationMasqueradeTo:                       "default.example.io",
+	}}}
+
+	tests := []struct {
+		name         string
+		defaultClass bool
+		ingress      *networkingv1.Ingress
+		target       string
+	}{
+		{name: "annotation of class", ingress: newIngress("annotated", nil, networkingv1.IngressLoadBalancerIngress{IP: "10.0.0.1"}), target: "annotated.example.io"},
+		{name: "annotation of class referenced by legacy annotation", ingress: newIngress("", map[string]string{annotationIngressClassLegacy: "annotated"}), target: "annotated.example.io"},
+		{name: "annotation of default class", defaultClass: true, ingress: newIngress("", nil, networkingv1.IngressLoadBalancerIngress{IP: "10.0.0.1"}), target: "default.example.io"},
+		{name: "no default class", ingress: newIngress("", nil), target: ""},
+		{name: "service matched by ip (first by name)", ingress: newIngress("nginx", nil, networkingv1.IngressLoadBalancerIngress{IP: "10.0.0.1"}), target: "controller.ingress-nginx.svc.cluster.local"},
+		{name: "service matched by second ip", ingress: newIngress("nginx", nil, networkingv1.IngressLoadBalancerIngress{IP: "10.0.0.2"}), target: "controller-b.ingress-nginx.svc.cluster.local"},
+		{name: "service matched by hostname", ingress: newIngress("nginx", nil, networkingv1.IngressLoadBalancerIngress{Hostname: "lb.example.io"}), target: "controller.ingress-alb.svc.cluster.local"},
+		{name: "services matched by multiple addresses (first by namespace)", ingress: newIngress("nginx", nil, networkingv1.IngressLoadBalancerIngress{IP: "10.0.0.2"}, networkingv1.IngressLoadBalancerIngress{Hostname: "lb.example.io"}), target: "controller.ingress-alb.svc.cluster.local"},
+		{name: "non-load balancer service ignored, fallback to ip", ingress: newIngress("nginx", nil, networkingv1.IngressLoadBalancerIngress{IP: "10.0.0.3"}), target: "10.0.0.3"},
+		{name: "fallback to hostname", ingress: newIngress("nginx", nil, networkingv1.IngressLoadBalancerIngress{Hostname: "other.example.io"}), target: "other.example.io"},
+		{name: "no load balancer status", ingress: newIngress("nginx", nil), target: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objs := objects
+			if tt.defaultClass {
+				objs = append(objs[:len(objs):len(objs)], defaultClass)
+			}
+			c := newIngressTestClient(t, objs...)
+			target, err := getIngressTarget(context.Background(), c, tt.ingress, "cluster.local")
+			if err != nil {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+			if target != tt.target {
+				t.Errorf("got target %q, want %q", target, tt.target)
+			}
+		})
+	}
+}
+
+func TestIngressTargets(t *testing.T) {
+	c := newIngressTestClient(t,
+		&networkingv1.IngressClass{ObjectMeta: metav1.ObjectMeta{Name: "nginx"}},
+		&networkingv1.IngressClass{ObjectMeta: metav1.ObjectMeta{Name: "internal", Annotations: map[string]string{annotationMasqueradeTo: "class.example.io"}}},
+		newLoadBalancerService("ingress-nginx", "controller", corev1.ServiceTypeLoadBalancer, corev1.LoadBalancerIngress{IP: "10.0.0.1"}),
+	)
+	classes := ClassFilter{"nginx": "", "internal": "internal.example.io"}
+	reconciler := &IngressReconciler{Client: c, Classes: classes, ClusterDomain: "cluster.local"}
+
+	newRulesIngress := func(className string, annotations map[string]string, hosts ...string) *networkingv1.Ingress {
+		ingress := newIngress(className, annotations, networkingv1.IngressLoadBalancerIngress{IP: "10.0.0.1"})
+		for _, host := range hosts {
+			ingress.Spec.Rules = append(ingress.Spec.Rules, networkingv1.IngressRule{Host: host})
+		}
+		return ingress
+	}
+
+	tests := []struct {
+		name    string
+		ingress *networkingv1.Ingress
+		targets map[string]string
+	}{
+		{
+			name:    "derived from load balancer service",
+			ingress: newRulesIngress("nginx", nil, "www.example.io", "api.example.io", ""),
+			targets: map[string]string{"www.example.io": "controller.ingress-nginx.svc.cluster.local", "api.example.io": "controller.ingress-nginx.svc.cluster.local"},
+		},
+		{
+			name:    "default target of class filter takes precedence over ingress class annotation",
+			ingress: newRulesIngress("internal", nil, "www.example.io"),
+			targets: map[string]string{"www.example.io": "internal.example.io"},
+		},
+		{
+			name:    "default target of class filter not used if automatic target is disabled",
+			ingress: newRulesIngress("internal", map[string]string{annotationMasqueradeToAuto: "false"}, "www.example.io"),
+			targets: nil,
+		},
+		{
+			name:    "annotation takes precedence",
+			ingress: newRulesIngress("internal", map[string]string{annotationMasqueradeTo: "custom.example.io"}, "www.example.io"),
+			targets: map[string]string{"www.example.io": "custom.example.io"},
+		},
+		{
+			name:    "class not matching filter",
+			ingress: newRulesIngress("other", nil, "www.example.io"),
+			targets: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets, err := reconciler.GetTargets(context.Background(), tt.ingress)
+			if err != nil {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+			if len(targets) != 0 || len(tt.targets) != 0 {
+				if !reflect.DeepEqual(targets, tt.targets) {
+					t.Errorf("got targets %v, want %v", targets, tt.targets)
+				}
+			}
+		})
+	}
+}
+
+func TestIndexIngressClassName(t *testing.T) {
+	tests := []struct {
+		name    string
+		ingress *networkingv1.Ingress
+		value   string
+	}{
+		{name: "spec", ingress: newIngress("nginx", nil), value: "nginx"},
+		{name: "legacy annotation", ingress: newIngress("", map[string]string{annotationIngressClassLegacy: "nginx"}), value: "nginx"},
+		{name: "spec takes precedence", ingress: newIngress("nginx", map[string]string{annotationIngressClassLegacy: "other"}), value: "nginx"},
+		{name: "no class", ingress: newIngress("", nil), value: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if values := indexIngressClassName(tt.ingress); !reflect.DeepEqual(values, []string{tt.value}) {
+				t.Errorf("got %v, want %v", values, []string{tt.value})
+			}
+		})
+	}
+}
@@ -7,20 +7,42 @@ package controllers
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/sap/go-generics/maps"
 
 	istionetworkingv1beta1 "istio.io/client-go/pkg/apis/networking/v1beta1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	dnsv1alpha1 "github.com/sap/dns-masquerading-operator/api/v1alpha1"
+)
+
+const (
+	// field index of services, holding the labels of their selector (as key=value pairs)
+	serviceSelectorIndex = "spec.selector"
+	// field index of istio gateways, holding the labels of their selector (as key=value pairs)
+	gatewaySelectorIndex = "spec.selector"
 )
 
 // GatewayReconciler reconciles a Gateway object
 type GatewayReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme        *runtime.Scheme
+	Recorder      record.EventRecorder
+	Filter        *SourceFilter
+	Classes       ClassFilter
+	ClusterDomain string
 }
 
 // Reconcile a gateway resource
@@ -38,28 +60,236 @@ func (r *GatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (re
 		return ctrl.Result{}, nil
 	}
 
-	if err := manageDependents(ctx, r.Client, gateway, getHostsFromGateway(gateway)); err != nil {
+	// Clean up masquerading rules of objects not matching the filters (any longer)
+	classTarget, matches := r.matchFilters(gateway)
+	if !matches {
+		log.V(1).Info("not matching filter; ignoring")
+		if err := manageDependentsWithTargets(ctx, r.Client, gateway, nil); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	targets, err := r.getTargets(ctx, gateway, classTarget)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := manageDependentsWithTargets(ctx, r.Client, gateway, targets); err != nil {
 		return ctrl.Result{}, err
 	}
 
 	return ctrl.Result{}, nil
 }
 
-// getHostsFromGateway extracts hosts of a gateway resource
+// GetTargets determines the masquerading rules (hosts mapped to rewrite targets) which would be maintained for given gateway;
+// in contrast to the reconciliation, invalid hosts and invalid entries of the masquerade-map annotation are not skipped, but returned as error
+func (r *GatewayReconciler) GetTargets(ctx context.Context, obj client.Object) (map[string]string, error) {
+	gateway, ok := obj.(*istionetworkingv1beta1.Gateway)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type %T", obj)
+	}
+	classTarget, matches := r.matchFilters(gateway)
+	if !matches {
+		return nil, nil
+	}
+	if err := validateAnnotations(gateway, getHostsFromGateway(gateway)...); err != nil {
+		return nil, err
+	}
+	return r.getTargets(ctx, gateway, classTarget)
+}
+
+// check if a gateway matches the filters; if it matches the selector filter, the default target of the matching selector label is returned as well
+func (r *GatewayReconciler) matchFilters(gateway *istionetworkingv1beta1.Gateway) (string, bool) {
+	if !r.Filter.Matches(gateway) {
+		return "", false
+	}
+	return r.Classes.Match(getGatewaySelectorLabels(gateway)...)
+}
+
+// determine masquerading rules (hosts mapped to rewrite targets) of a gateway; classTarget is the default target provided by the
+// selector filter (if any)
+func (r *GatewayReconciler) getTargets(ctx context.Context, gateway *istionetworkingv1beta1.Gateway, classTarget string) (map[string]string, error) {
+	defaultTo := ""
+	if autoTargetEnabled(gateway) {
+		defaultTo = classTarget
+		if defaultTo == "" {
+			var err error
+			defaultTo, err = getGatewayTarget(ctx, r.Client, gateway, r.ClusterDomain)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return getTargets(ctx, r.Client, r.Recorder, gateway, getHosts(ctx, gateway, getHostsFromGateway(gateway)...), defaultTo)
+}
+
+// getHostsFromGateway extracts hosts of a gateway resource (a namespace prefix, as in 'ns/host', is stripped)
 func getHostsFromGateway(gateway *istionetworkingv1beta1.Gateway) []string {
-	// TODO: consider external-dns.alpha.kubernetes.io/hostname annotation as well ?
 	hosts := make(map[string]struct{})
 	for _, server := range gateway.Spec.Servers {
 		for _, host := range server.Hosts {
+			if i := strings.Index(host, "/"); i >= 0 {
+				host = host[i+1:]
+			}
 			hosts[host] = struct{}{}
 		}
 	}
 	return maps.Keys(hosts)
 }
 
+// getGatewaySelectorLabels returns the labels of the selector of an istio gateway, as sorted list of key=value pairs
+func getGatewaySelectorLabels(gateway *istionetworkingv1beta1.Gateway) []string {
+	var labels []string
+	for key, value := range gateway.Spec.GetSelector() {
+		labels = append(labels, key+"="+value)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// getGatewayTarget determines the in-cluster address of an istio gateway; that is, the address of the service selecting the gateway workload;
+// returns an empty string if nothing was found
+func getGatewayTarget(ctx context.Context, c client.Client, gateway *istionetworkingv1beta1.Gateway, clusterDomain string) (string, error) {
+	service, err := getGatewayService(ctx, c, gateway)
+	if err != nil || service == nil {
+		return "", err
+	}
+	return getServiceAddress(service, clusterDomain), nil
+}
+
+// getGatewayService returns the service selecting the workload of an istio gateway (such that the service's selector contains all labels
+// of the gateway's selector); if there are multiple such services, services in the gateway's namespace are preferred, then services
+// of type LoadBalancer; returns nil if nothing was found
+func getGatewayService(ctx context.Context, c client.Client, gateway *istionetworkingv1beta1.Gateway) (*corev1.Service, error) {
+	if len(gateway.Spec.GetSelector()) == 0 {
+		return nil, nil
+	}
+
+	// note: a matching service must contain every label of the gateway's selector, so it suffices to look up one of them
+	serviceList := &corev1.ServiceList{}
+	if err := c.List(ctx, serviceList, client.MatchingFields{serviceSelectorIndex: getGatewaySelectorLabels(gateway)[0]}); err != nil {
+		return nil, errors.Wrap(err, "failed to list services")
+	}
+	var services []*corev1.Service
+	for i := range serviceList.Items {
+		service := &serviceList.Items[i]
+		if matchesGatewaySelector(service, gateway) {
+			services = append(services, service)
+		}
+	}
+	if len(services) == 0 {
+		return nil, nil
+	}
+
+	rank := func(service *corev1.Service) int {
+		rank := 0
+		if service.Namespace != gateway.Namespace {
+			rank += 2
+		}
+		if service.Spec.Type != corev1.ServiceTypeLoadBalancer {
+			rank += 1
+		}
+		return rank
+	}
+	sort.Slice(services, func(i, j int) bool {
+		if ri, rj := rank(services[i]), rank(services[j]); ri != rj {
+			return ri < rj
+		}
+		if services[i].Namespace != services[j].Namespace {
+			return services[i].Namespace < services[j].Namespace
+		}
+		return services[i].Name < services[j].Name
+	})
+	return services[0], nil
+}
+
+// check if the selector of given service contains all labels of the selector of given gateway
+func matchesGatewaySelector(service *corev1.Service, gateway *istionetworkingv1beta1.Gateway) bool {
+	selector := gateway.Spec.GetSelector()
+	if len(selector) == 0 {
+		return false
+	}
+	for key, value := range selector {
+		if v, ok := service.Spec.Selector[key]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// getGatewaysForService returns the istio gateways whose workload is selected by given service (see matchesGatewaySelector())
+func getGatewaysForService(ctx context.Context, c client.Client, service *corev1.Service) ([]*istionetworkingv1beta1.Gateway, error) {
+	// note: the selector of a matching gateway consists of labels of the service's selector, so it suffices to look up each of these
+	var gateways []*istionetworkingv1beta1.Gateway
+	seen := make(map[types.NamespacedName]struct{})
+	for _, label := range getServiceSelectorLabels(service) {
+		gatewayList := &istionetworkingv1beta1.GatewayList{}
+		if err := c.List(ctx, gatewayList, client.MatchingFields{gatewaySelectorIndex: label}); err != nil {
+			return nil, errors.Wrap(err, "failed to list gateways")
+		}
+		for _, gateway := range gatewayList.Items {
+			key := types.NamespacedName{Namespace: gateway.Namespace, Name: gateway.Name}
+			if _, ok := seen[key]; ok || !matchesGatewaySelector(service, gateway) {
+				continue
+			}
+			seen[key] = struct{}{}
+			gateways = append(gateways, gateway)
+		}
+	}
+	return gateways, nil
+}
+
+// getServiceSelectorLabels returns the labels of the selector of a service, as sorted list of key=value pairs
+func getServiceSelectorLabels(service *corev1.Service) []string {
+	var labels []string
+	for key, value := range service.Spec.Selector {
+		labels = append(labels, key+"="+value)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// index function for serviceSelectorIndex
+func indexServiceSelector(obj client.Object) []string {
+	return getServiceSelectorLabels(obj.(*corev1.Service))
+}
+
+// index function for gatewaySelectorIndex
+func indexGatewaySelector(obj client.Object) []string {
+	return getGatewaySelectorLabels(obj.(*istionetworkingv1beta1.Gateway))
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *GatewayReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &corev1.Service{}, serviceSelectorIndex, indexServiceSelector); err != nil {
+		return errors.Wrapf(err, "failed to register field index %s", serviceSelectorIndex)
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &istionetworkingv1beta1.Gateway{}, gatewaySelectorIndex, indexGatewaySelector); err != nil {
+		return errors.Wrapf(err, "failed to register field index %s", gatewaySelectorIndex)
+	}
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&istionetworkingv1beta1.Gateway{}).
+		For(&istionetworkingv1beta1.Gateway{}, builder.WithPredicates(r.Filter.Predicate(), masqueradingStatusPredicate())).
+		Owns(&dnsv1alpha1.MasqueradingRule{}, builder.WithPredicates(masqueradingRulePredicate())).
+		Watches(
+			&corev1.Service{},
+			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+				log := ctrl.LoggerFrom(ctx)
+				service, ok := obj.(*corev1.Service)
+				if !ok || len(service.Spec.Selector) == 0 {
+					return nil
+				}
+				gateways, err := getGatewaysForService(ctx, r.Client, service)
+				if err != nil {
+					log.Error(err, "failed to list gateways")
+					return nil
+				}
+				var requests []reconcile.Request
+				for _, gateway := range gateways {
+					requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: gateway.Namespace, Name: gateway.Name}})
+				}
+				return requests
+			}),
+		).
 		Complete(r)
 }
@@ -0,0 +1,114 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	istioapinetworkingv1beta1 "istio.io/api/networking/v1beta1"
+	istionetworkingv1beta1 "istio.io/client-go/pkg/apis/networking/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newIstioGatewayTestClient(t *testing.T, objects ...client.Object) client.Client {
+	t.Helper()
+	return newFakeClientBuilder(t).
+		WithObjects(objects...).
+		WithIndex(&corev1.Service{}, serviceSelectorIndex, indexServiceSelector).
+		WithIndex(&istionetworkingv1beta1.Gateway{}, gatewaySelectorIndex, indexGatewaySelector).
+		Build()
+}
+
+func newSelectorService(namespace string, name string, serviceType corev1.ServiceType, selector map[string]string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       corev1.ServiceSpec{Type: serviceType, Selector: selector},
+	}
+}
+
+func newIstioGateway(namespace string, name string, selector map[string]string) *istionetworkingv1beta1.Gateway {
+	return &istionetworkingv1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       istioapinetworkingv1beta1.Gateway{Selector: selector},
+	}
+}
+
+func TestGetGatewayService(t *testing.T) {
+	c := newIstioGatewayTestClient(t,
+		newSelectorService("istio-system", "ingressgateway", corev1.ServiceTypeLoadBalancer, map[string]string{"istio": "ingressgateway", "app": "gateway"}),
+		newSelectorService("istio-system", "ingressgateway-internal", corev1.ServiceTypeClusterIP, map[string]string{"istio": "ingressgateway", "app": "gateway"}),
+		newSelectorService("default", "ingressgateway-local", corev1.ServiceTypeClusterIP, map[string]string{"istio": "ingressgateway"}),
+		newSelectorService("istio-system", "egressgateway", corev1.ServiceTypeLoadBalancer, map[string]string{"istio": "egressgateway"}),
+		newSelectorService("istio-system", "partial", corev1.ServiceTypeLoadBalancer, map[string]string{"app": "gateway"}),
+	)
+
+	tests := []struct {
+		name     string
+		gateway  *istionetworkingv1beta1.Gateway
+		expected string
+	}{
+		{name: "service in gateway namespace is preferred", gateway: newIstioGateway("default", "test", map[string]string{"istio": "ingressgateway"}), expected: "default/ingressgateway-local"},
+		{name: "load balancer service is preferred", gateway: newIstioGateway("other", "test", map[string]string{"istio": "ingressgateway"}), expected: "istio-system/ingressgateway"},
+		{name: "all selector labels must match", gateway: newIstioGateway("default", "test", map[string]string{"istio": "ingressgateway", "app": "gateway"}), expected: "istio-system/ingressgateway"},
+		{name: "no matching service", gateway: newIstioGateway("default", "test", map[string]string{"istio": "other"}), expected: ""},
+		{name: "no selector", gateway: newIstioGateway("default", "test", nil), expected: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, err := getGatewayService(context.TODO(), c, tt.gateway)
+			if err != nil {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+			name := ""
+			if service != nil {
+				name = service.Namespace + "/" + service.Name
+			}
+			if name != tt.expected {
+				t.Errorf("got service %q, want %q", name, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetGatewaysForService(t *testing.T) {
+	c := newIstioGatewayTestClient(t,
+		newIstioGateway("default", "ingress", map[string]string{"istio": "ingressgateway"}),
+		newIstioGateway("other", "ingress-app", map[string]string{"istio": "ingressgateway", "app": "gateway"}),
+		newIstioGateway("default", "egress", map[string]string{"istio": "egressgateway"}),
+		newIstioGateway("default", "foreign", map[string]string{"istio": "ingressgateway", "app": "other"}),
+		newIstioGateway("default", "no-selector", nil),
+	)
+
+	tests := []struct {
+		name     string
+		service  *corev1.Service
+		expected []string
+	}{
+		{name: "gateways with subset selector", service: newSelectorService("istio-system", "test", corev1.ServiceTypeLoadBalancer, map[string]string{"istio": "ingressgateway", "app": "gateway"}), expected: []string{"default/ingress", "other/ingress-app"}},
+		{name: "gateways with equal selector", service: newSelectorService("istio-system", "test", corev1.ServiceTypeClusterIP, map[string]string{"istio": "egressgateway"}), expected: []string{"default/egress"}},
+		{name: "no matching gateway", service: newSelectorService("istio-system", "test", corev1.ServiceTypeClusterIP, map[string]string{"app": "gateway"}), expected: nil},
+		{name: "no selector", service: newSelectorService("istio-system", "test", corev1.ServiceTypeClusterIP, nil), expected: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateways, err := getGatewaysForService(context.TODO(), c, tt.service)
+			if err != nil {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+			var names []string
+			for _, gateway := range gateways {
+				names = append(names, gateway.Namespace+"/"+gateway.Name)
+			}
+			if names := sorted(names); !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("got gateways %v, want %v", names, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/sap/go-generics/maps"
+
+	istionetworkingv1beta1 "istio.io/client-go/pkg/apis/networking/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dnsv1alpha1 "github.com/sap/dns-masquerading-operator/api/v1alpha1"
+)
+
+// ServiceEntryReconciler reconciles a ServiceEntry object
+type ServiceEntryReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	Recorder      record.EventRecorder
+	Filter        *SourceFilter
+	ClusterDomain string
+}
+
+// Reconcile a service entry resource
+func (r *ServiceEntryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	log := ctrl.LoggerFrom(ctx)
+	log.V(1).Info("running reconcile")
+
+	// Retrieve target service entry
+	serviceEntry := &istionetworkingv1beta1.ServiceEntry{}
+	if err := r.Get(ctx, req.NamespacedName, serviceEntry); err != nil {
+		if err := client.IgnoreNotFound(err); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "unexpected get error")
+		}
+		log.Info("not found; ignoring")
+		return ctrl.Result{}, nil
+	}
+
+	// Clean up masquerading rules of objects not matching the filter (any longer)
+	if !r.Filter.Matches(serviceEntry) {
+		log.V(1).Info("not matching filter; ignoring")
+		if err := manageDependentsWithTargets(ctx, r.Client, serviceEntry, nil); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	services, err := getExistingServiceHosts(ctx, r.Client, serviceEntry.Spec.Hosts)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := manageDependents(ctx, r.Client, r.Recorder, serviceEntry, getHosts(ctx, serviceEntry, getHostsFromServiceEntry(serviceEntry, r.ClusterDomain, services)...), ""); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// getHostsFromServiceEntry extracts hosts of a service entry resource
+func getHostsFromServiceEntry(serviceEntry *istionetworkingv1beta1.ServiceEntry, clusterDomain string, services map[string]struct{}) []string {
+	hosts := make(map[string]struct{})
+	for _, host := range serviceEntry.Spec.Hosts {
+		if !isMeshInternalHost(host, clusterDomain, services) {
+			hosts[host] = struct{}{}
+		}
+	}
+	return maps.Keys(hosts)
+}
+
+// index function for serviceHostIndex on service entries
+func indexServiceEntryServiceHosts(obj client.Object) []string {
+	return getServiceHosts(obj.(*istionetworkingv1beta1.ServiceEntry).Spec.Hosts)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ServiceEntryReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &istionetworkingv1beta1.ServiceEntry{}, serviceHostIndex, indexServiceEntryServiceHosts); err != nil {
+		return errors.Wrapf(err, "failed to register field index %s", serviceHostIndex)
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&istionetworkingv1beta1.ServiceEntry{}, builder.WithPredicates(r.Filter.Predicate(), masqueradingStatusPredicate())).
+		Owns(&dnsv1alpha1.MasqueradingRule{}, builder.WithPredicates(masqueradingRulePredicate())).
+		// note: creation or deletion of a service may change whether a host of the form name.namespace is mesh-internal
+		Watches(&corev1.Service{}, enqueueForServiceHost(r.Client, func() client.ObjectList { return &istionetworkingv1beta1.ServiceEntryList{} }), builder.WithPredicates(existencePredicate())).
+		Complete(r)
+}
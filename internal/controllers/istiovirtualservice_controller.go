@@ -0,0 +1,207 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sap/go-generics/maps"
+
+	istionetworkingv1beta1 "istio.io/client-go/pkg/apis/networking/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	dnsv1alpha1 "github.com/sap/dns-masquerading-operator/api/v1alpha1"
+)
+
+const (
+	// field index of istio virtual services and service entries, holding their hosts of the form name.namespace
+	// (which are mesh-internal if a service with that name exists in that namespace)
+	serviceHostIndex = "spec.hosts"
+)
+
+// VirtualServiceReconciler reconciles a VirtualService object
+type VirtualServiceReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	Recorder      record.EventRecorder
+	Filter        *SourceFilter
+	ClusterDomain string
+}
+
+// Reconcile a virtual service resource
+func (r *VirtualServiceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	log := ctrl.LoggerFrom(ctx)
+	log.V(1).Info("running reconcile")
+
+	// Retrieve target virtual service
+	virtualService := &istionetworkingv1beta1.VirtualService{}
+	if err := r.Get(ctx, req.NamespacedName, virtualService); err != nil {
+		if err := client.IgnoreNotFound(err); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "unexpected get error")
+		}
+		log.Info("not found; ignoring")
+		return ctrl.Result{}, nil
+	}
+
+	// Clean up masquerading rules of objects not matching the filter (any longer)
+	if !r.Filter.Matches(virtualService) {
+		log.V(1).Info("not matching filter; ignoring")
+		if err := manageDependentsWithTargets(ctx, r.Client, virtualService, nil); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	services, err := getExistingServiceHosts(ctx, r.Client, virtualService.Spec.Hosts)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := manageDependents(ctx, r.Client, r.Recorder, virtualService, getHosts(ctx, virtualService, getHostsFromVirtualService(virtualService, r.ClusterDomain, services)...), ""); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// getHostsFromVirtualService extracts hosts of a virtual service resource
+func getHostsFromVirtualService(virtualService *istionetworkingv1beta1.VirtualService, clusterDomain string, services map[string]struct{}) []string {
+	hosts := make(map[string]struct{})
+	for _, host := range virtualService.Spec.Hosts {
+		if !isMeshInternalHost(host, clusterDomain, services) {
+			hosts[host] = struct{}{}
+		}
+	}
+	return maps.Keys(hosts)
+}
+
+// check if given host (as specified on virtual services or service entries) is not an external DNS name;
+// that is, if it is a short name (to be expanded by istio relative to the resource's namespace), the catch-all host '*',
+// a namespace-qualified short name of an existing service (name.namespace, contained in the given services),
+// or a (possibly partially qualified) cluster-local service name (name.namespace.svc, name.namespace.svc.cluster, ...)
+func isMeshInternalHost(host string, clusterDomain string, services map[string]struct{}) bool {
+	if host == "" || host == "*" || !strings.Contains(host, ".") {
+		return true
+	}
+	if _, ok := services[host]; ok {
+		return true
+	}
+	suffix := ".svc"
+	if strings.HasSuffix(host, suffix) {
+		return true
+	}
+	for _, label := range strings.Split(clusterDomain, ".") {
+		suffix += "." + label
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// return those of the given hosts which are of the form name.namespace
+func getServiceHosts(hosts []string) []string {
+	var serviceHosts []string
+	for _, host := range hosts {
+		if _, namespace, ok := strings.Cut(host, "."); ok && namespace != "" && !strings.Contains(namespace, ".") {
+			serviceHosts = append(serviceHosts, host)
+		}
+	}
+	return serviceHosts
+}
+
+// return those of the given hosts which are of the form name.namespace, such that a service with that name exists in that namespace
+func getExistingServiceHosts(ctx context.Context, c client.Client, hosts []string) (map[string]struct{}, error) {
+	services := make(map[string]struct{})
+	for _, host := range getServiceHosts(hosts) {
+		name, namespace, _ := strings.Cut(host, ".")
+		if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &corev1.Service{}); err != nil {
+			if err := client.IgnoreNotFound(err); err != nil {
+				return nil, errors.Wrapf(err, "failed to get service %s/%s", namespace, name)
+			}
+			continue
+		}
+		services[host] = struct{}{}
+	}
+	return services, nil
+}
+
+// return the objects (such as virtual services or service entries) of the given list type, having the given service
+// among their hosts in the form name.namespace
+func getObjectsForServiceHost(ctx context.Context, c client.Client, list client.ObjectList, service *corev1.Service) ([]client.Object, error) {
+	if err := c.List(ctx, list, client.MatchingFields{serviceHostIndex: service.Name + "." + service.Namespace}); err != nil {
+		return nil, errors.Wrap(err, "failed to list objects")
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to extract objects")
+	}
+	objects := make([]client.Object, len(items))
+	for i, item := range items {
+		objects[i] = item.(client.Object)
+	}
+	return objects, nil
+}
+
+// return handler enqueuing the objects (such as virtual services or service entries) of the given list type,
+// having the service of the handled event among their hosts in the form name.namespace
+func enqueueForServiceHost(c client.Client, newList func() client.ObjectList) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+		log := ctrl.LoggerFrom(ctx)
+		service, ok := obj.(*corev1.Service)
+		if !ok {
+			return nil
+		}
+		objects, err := getObjectsForServiceHost(ctx, c, newList(), service)
+		if err != nil {
+			log.Error(err, "failed to list objects referencing service", "namespace", service.Namespace, "name", service.Name)
+			return nil
+		}
+		var requests []reconcile.Request
+		for _, object := range objects {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: object.GetNamespace(), Name: object.GetName()}})
+		}
+		return requests
+	})
+}
+
+// custom predicate to filter for creations and deletions of objects (updates do not change their existence)
+func existencePredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool { return false },
+	}
+}
+
+// index function for serviceHostIndex on virtual services
+func indexVirtualServiceServiceHosts(obj client.Object) []string {
+	return getServiceHosts(obj.(*istionetworkingv1beta1.VirtualService).Spec.Hosts)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *VirtualServiceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &istionetworkingv1beta1.VirtualService{}, serviceHostIndex, indexVirtualServiceServiceHosts); err != nil {
+		return errors.Wrapf(err, "failed to register field index %s", serviceHostIndex)
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&istionetworkingv1beta1.VirtualService{}, builder.WithPredicates(r.Filter.Predicate(), masqueradingStatusPredicate())).
+		Owns(&dnsv1alpha1.MasqueradingRule{}, builder.WithPredicates(masqueradingRulePredicate())).
+		// note: creation or deletion of a service may change whether a host of the form name.namespace is mesh-internal
+		Watches(&corev1.Service{}, enqueueForServiceHost(r.Client, func() client.ObjectList { return &istionetworkingv1beta1.VirtualServiceList{} }), builder.WithPredicates(existencePredicate())).
+		Complete(r)
+}
@@ -0,0 +1,116 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	istionetworkingv1beta1 "istio.io/client-go/pkg/apis/networking/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsMeshInternalHost(t *testing.T) {
+	services := map[string]struct{}{"reviews.default": {}, "istiod.istio-system": {}}
+
+	tests := []struct {
+		host     string
+		internal bool
+	}{
+		{host: "", internal: true},
+		{host: "*", internal: true},
+		{host: "reviews", internal: true},
+		{host: "reviews.default", internal: true},
+		{host: "ratings.default", internal: false},
+		{host: "reviews.unknown", internal: false},
+		{host: "reviews.default.svc", internal: true},
+		{host: "reviews.default.svc.cluster", internal: true},
+		{host: "reviews.default.svc.cluster.local", internal: true},
+		{host: "reviews.default.svc.other.local", internal: false},
+		{host: "reviews.default.svc.clusterx", internal: false},
+		{host: "www.example.io", internal: false},
+		{host: "example.io", internal: false},
+		{host: "*.example.io", internal: false},
+		{host: "default.example.io", internal: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			if internal := isMeshInternalHost(tt.host, "cluster.local", services); internal != tt.internal {
+				t.Errorf("got %t, want %t", internal, tt.internal)
+			}
+		})
+	}
+}
+
+func TestGetHostsFromVirtualService(t *testing.T) {
+	virtualService := &istionetworkingv1beta1.VirtualService{}
+	virtualService.Spec.Hosts = []string{"www.example.io", "reviews", "reviews.default", "reviews.default.svc.cluster", "*", "www.example.io", "api.example.io", "example.io"}
+
+	expected := []string{"api.example.io", "example.io", "www.example.io"}
+	if hosts := sorted(getHostsFromVirtualService(virtualService, "cluster.local", map[string]struct{}{"reviews.default": {}})); !reflect.DeepEqual(hosts, expected) {
+		t.Errorf("got hosts %v, want %v", hosts, expected)
+	}
+}
+
+func TestGetExistingServiceHosts(t *testing.T) {
+	c := newFakeClientBuilder(t).
+		WithObjects(
+			&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "reviews"}},
+			&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "io", Name: "other"}},
+		).
+		Build()
+
+	services, err := getExistingServiceHosts(context.Background(), c, []string{"reviews", "reviews.default", "ratings.default", "example.io", "www.example.io", "reviews.default.svc"})
+	if err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	expected := map[string]struct{}{"reviews.default": {}}
+	if !reflect.DeepEqual(services, expected) {
+		t.Errorf("got services %v, want %v", services, expected)
+	}
+}
+
+func TestGetObjectsForServiceHost(t *testing.T) {
+	newVirtualService := func(namespace string, name string, hosts ...string) *istionetworkingv1beta1.VirtualService {
+		virtualService := &istionetworkingv1beta1.VirtualService{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+		virtualService.Spec.Hosts = hosts
+		return virtualService
+	}
+	c := newFakeClientBuilder(t).
+		WithObjects(
+			newVirtualService("default", "a", "reviews.default", "www.example.io"),
+			newVirtualService("other", "b", "reviews.default"),
+			newVirtualService("default", "c", "reviews", "reviews.default.svc"),
+		).
+		WithIndex(&istionetworkingv1beta1.VirtualService{}, serviceHostIndex, indexVirtualServiceServiceHosts).
+		Build()
+
+	tests := []struct {
+		name     string
+		service  *corev1.Service
+		expected []string
+	}{
+		{name: "referenced service", service: &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "reviews"}}, expected: []string{"default/a", "other/b"}},
+		{name: "unreferenced service", service: &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ratings"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects, err := getObjectsForServiceHost(context.Background(), c, &istionetworkingv1beta1.VirtualServiceList{}, tt.service)
+			if err != nil {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+			var keys []string
+			for _, object := range objects {
+				keys = append(keys, object.GetNamespace()+"/"+object.GetName())
+			}
+			if keys := sorted(keys); !reflect.DeepEqual(keys, tt.expected) {
+				t.Errorf("got objects %v, want %v", keys, tt.expected)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"net"
 	"regexp"
 	"time"
 
@@ -30,6 +31,7 @@ import (
 
 	dnsv1alpha1 "github.com/sap/dns-masquerading-operator/api/v1alpha1"
 	"github.com/sap/dns-masquerading-operator/internal/coredns"
+	"github.com/sap/dns-masquerading-operator/internal/dnsname"
 	"github.com/sap/dns-masquerading-operator/internal/webhooks"
 )
 
@@ -47,6 +49,12 @@ type MasqueradingRuleReconciler struct {
 	CorednsConfigMapKey         string
 	CorednsConfigMapUpdateDelay time.Duration
 	Resolver                    coredns.Resolver
+	// If true, release the finalizer of deleted rules only after the rewrite is no longer served by DNS
+	// (or the deletion verification timeout has passed)
+	VerifyDeletion              bool
+	DeletionVerificationTimeout time.Duration
+	// Cluster domain (used to expand short service names when defaulting)
+	ClusterDomain string
 }
 
 // TODO: add status info about the duration of the reconciliation
@@ -70,16 +78,20 @@ func (r *MasqueradingRuleReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	}
 	previousMasqueradingRuleStatus := masqueradingRule.Status.DeepCopy()
 
-	// Call the defaulting webhook logic also here (because defaulting through the webhook might be incomplete in case of generateName usage)
-	if err := (&webhooks.MasqueradingRuleWebhook{}).Default(ctx, masqueradingRule); err != nil {
+	// Call the defaulting webhook logic also here (because defaulting through the webhook might be incomplete in case of generateName usage);
+	// note: no client is passed, such that names of the form 'svc.ns' are not expanded here; that expansion depends on the existence
+	// of services (which may change at any time), and is therefore only done at admission time, where the result is persisted
+	if err := (&webhooks.MasqueradingRuleWebhook{ClusterDomain: r.ClusterDomain}).Default(ctx, masqueradingRule); err != nil {
 		return ctrl.Result{}, errors.Wrap(err, "error setting defaults")
 	}
 
 	// Acknowledge observed generation
 	masqueradingRule.Status.ObservedGeneration = masqueradingRule.Generation
 
-	// Always attempt to update the status
+	// Always attempt to update the status;
+	// note: the conflict condition is only re-evaluated if the conflict check ran (or the reconciliation succeeded)
 	skipStatusUpdate := false
+	conflictChecked := false
 	defer func() {
 		if skipStatusUpdate {
 			return
@@ -88,6 +100,11 @@ func (r *MasqueradingRuleReconciler) Reconcile(ctx context.Context, req ctrl.Req
 			masqueradingRule.SetState(dnsv1alpha1.MasqueradingRuleStateError, err.Error())
 			r.Recorder.Event(masqueradingRule, corev1.EventTypeWarning, "ReconciliationFailed", err.Error())
 		}
+		if conflictErr := (*coredns.ConflictError)(nil); errors.As(err, &conflictErr) {
+			masqueradingRule.SetConflict(true, conflictErr.Error())
+		} else if err == nil || conflictChecked {
+			masqueradingRule.SetConflict(false, "")
+		}
 		if updateErr := r.Status().Update(ctx, masqueradingRule, client.FieldOwner(fieldOwner)); updateErr != nil {
 			err = utilerrors.NewAggregate([]error{err, updateErr})
 			result = ctrl.Result{}
@@ -155,7 +172,10 @@ func (r *MasqueradingRuleReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	}
 
 	// Set owner identifier for later usage
-	owner := fmt.Sprintf("%s (%s/%s)", masqueradingRule.UID, masqueradingRule.Namespace, masqueradingRule.Name)
+	owner := coredns.RewriteRuleOwner(string(masqueradingRule.UID), masqueradingRule.Namespace, masqueradingRule.Name)
+
+	// Build rewrite rule (as rendered into the coredns custom config map) for later usage
+	rule, ruleErr := coredns.NewRewriteRule(owner, masqueradingRule.Spec.From, masqueradingRule.Spec.To)
 
 	// Do the reconciliation
 	// TODO: there is a race condition when worker counts > 1 are configured, while maintaining the coredns custom config map;
@@ -171,16 +191,20 @@ func (r *MasqueradingRuleReconciler) Reconcile(ctx context.Context, req ctrl.Req
 			}
 		}
 
-		rule, err := coredns.NewRewriteRule(owner, masqueradingRule.Spec.From, masqueradingRule.Spec.To)
-		if err != nil {
-			return ctrl.Result{}, errors.Wrap(err, "error adding rewrite rule")
+		if ruleErr != nil {
+			return ctrl.Result{}, errors.Wrap(ruleErr, "error adding rewrite rule")
 		}
+		masqueradingRule.Status.From = rule.From()
+		masqueradingRule.Status.FromUnicode = toUnicode(rule.From())
+		masqueradingRule.Status.To = rule.To()
+		masqueradingRule.Status.ToUnicode = toUnicode(rule.To())
 
 		if configMap == nil {
 			ruleset := coredns.NewRewriteRuleSet()
 			if _, err := ruleset.AddRule(rule); err != nil {
 				return ctrl.Result{}, errors.Wrap(err, "error adding rewrite rule")
 			}
+			conflictChecked = true
 			masqueradingRule.SetState(dnsv1alpha1.MasqueradingRuleStateProcessing, "waiting for masquerading rule to be reconciled")
 			configMap = &corev1.ConfigMap{
 				ObjectMeta: metav1.ObjectMeta{
@@ -205,6 +229,7 @@ func (r *MasqueradingRuleReconciler) Reconcile(ctx context.Context, req ctrl.Req
 			if err != nil {
 				return ctrl.Result{}, errors.Wrap(err, "error adding rewrite rule")
 			}
+			conflictChecked = true
 			if changed {
 				masqueradingRule.SetState(dnsv1alpha1.MasqueradingRuleStateProcessing, "waiting for masquerading rule to be reconciled")
 				if configMap.Data == nil {
@@ -238,7 +263,7 @@ func (r *MasqueradingRuleReconciler) Reconcile(ctx context.Context, req ctrl.Req
 			}
 		}
 
-		active, err := r.Resolver.CheckRecord(ctx, regexp.MustCompile(`^\*(.*)$`).ReplaceAllString(masqueradingRule.Spec.From, `wildcard$1`), masqueradingRule.Spec.To)
+		active, err := r.Resolver.CheckRecord(ctx, probeHost(rule.From()), rule.To())
 		if err != nil {
 			return ctrl.Result{}, errors.Wrap(err, "error check DNS record")
 		}
@@ -298,6 +323,28 @@ func (r *MasqueradingRuleReconciler) Reconcile(ctx context.Context, req ctrl.Req
 			}
 		}
 
+		// note: an invalid rule was never added to the coredns custom config map, so there is nothing to verify
+		if r.VerifyDeletion && ruleErr == nil && slices.Contains(masqueradingRule.Finalizers, finalizer) {
+			removed, err := r.Resolver.CheckRecordRemoved(ctx, probeHost(rule.From()), rule.To())
+			if err != nil {
+				r.Recorder.Eventf(masqueradingRule, corev1.EventTypeWarning, "DeletionVerificationFailed", "error checking removal of masquerading rule from DNS: %s", err)
+				log.V(1).Info("error checking DNS record removal", "error", err.Error())
+			}
+			if !removed {
+				if time.Now().Before(masqueradingRule.DeletionTimestamp.Add(r.DeletionVerificationTimeout)) {
+					if err != nil {
+						masqueradingRule.SetState(dnsv1alpha1.MasqueradingRuleStateDeleting, fmt.Sprintf("waiting for masquerading rule to be removed from DNS (check failed: %s)", err))
+					} else {
+						masqueradingRule.SetState(dnsv1alpha1.MasqueradingRuleStateDeleting, "waiting for masquerading rule to be removed from DNS")
+					}
+					log.V(1).Info("dns record still active; rechecking in 10s ...")
+					return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+				}
+				r.Recorder.Eventf(masqueradingRule, corev1.EventTypeWarning, "DeletionVerificationTimedOut", "masquerading rule still served by DNS after %s; releasing it anyway", r.DeletionVerificationTimeout)
+				log.Info("dns record still active, but deletion verification timed out; releasing finalizer")
+			}
+		}
+
 		if slices.Contains(masqueradingRule.Finalizers, finalizer) {
 			controllerutil.RemoveFinalizer(masqueradingRule, finalizer)
 			if err := r.Update(ctx, masqueradingRule, client.FieldOwner(fieldOwner)); err != nil {
@@ -312,13 +359,29 @@ func (r *MasqueradingRuleReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	}
 }
 
+// return the host name to be used when checking DNS for the given rule source (wildcards replaced by a concrete label)
+func probeHost(from string) string {
+	return regexp.MustCompile(`^\*(.*)$`).ReplaceAllString(from, `wildcard$1`)
+}
+
+// return Unicode form of a DNS name; IP addresses and invalid names are returned unchanged
+func toUnicode(name string) string {
+	if net.ParseIP(name) != nil {
+		return name
+	}
+	if s, err := dnsname.ToUnicode(name); err == nil {
+		return s
+	}
+	return name
+}
+
 // record an event for specified object
 func (r *MasqueradingRuleReconciler) createEventForObject(ctx context.Context, gvk schema.GroupVersionKind, namespace string, name string, eventType string, reason string, message string, args ...interface{}) error {
-	owner, err := r.Scheme.New(gvk)
+	owner, err := newObjectForGroupVersionKind(r.Scheme, gvk)
 	if err != nil {
 		return err
 	}
-	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, owner.(client.Object)); err != nil {
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, owner); err != nil {
 		return err
 	}
 	r.Recorder.Eventf(owner, eventType, reason, message, args...)
@@ -0,0 +1,206 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sap/go-generics/slices"
+
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dnsv1alpha1 "github.com/sap/dns-masquerading-operator/api/v1alpha1"
+	"github.com/sap/dns-masquerading-operator/internal/coredns"
+)
+
+// resolver returning fixed results (and recording the checked host/result pairs)
+type fakeResolver struct {
+	active  bool
+	err     error
+	checked [][2]string
+}
+
+func (r *fakeResolver) CheckRecord(ctx context.Context, host string, expectedResult string) (bool, error) {
+	r.checked = append(r.checked, [2]string{host, expectedResult})
+	return r.active, r.err
+}
+
+func (r *fakeResolver) CheckRecordRemoved(ctx context.Context, host string, formerResult string) (bool, error) {
+	r.checked = append(r.checked, [2]string{host, formerResult})
+	return !r.active, r.err
+}
+
+func (r *fakeResolver) Close() {
+}
+
+// render a rewrite rule set consisting of the given rules (of the form owner, from, to)
+func renderRewriteRules(t *testing.T, rules ...[3]string) string {
+	t.Helper()
+	ruleset := coredns.NewRewriteRuleSet()
+	for _, r := range rules {
+		rule, err := coredns.NewRewriteRule(r[0], r[1], r[2])
+		if err != nil {
+			t.Fatalf("got unexpected error: %s", err)
+		}
+		if _, err := ruleset.AddRule(rule); err != nil {
+			t.Fatalf("got unexpected error: %s", err)
+		}
+	}
+	return ruleset.String()
+}
+
+func TestMasqueradingRuleReconcilerConflictCondition(t *testing.T) {
+	masqueradingRule := &dnsv1alpha1.MasqueradingRule{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test", UID: types.UID("test-uid"), Finalizers: []string{finalizer}},
+		Spec:       dnsv1alpha1.MasqueradingRuleSpec{From: "www.example.io", To: "1.2.3.4"},
+		Status:     dnsv1alpha1.MasqueradingRuleStatus{State: dnsv1alpha1.MasqueradingRuleStateProcessing},
+	}
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "coredns-custom"}}
+	c := newFakeClientBuilder(t).WithObjects(masqueradingRule, configMap).WithStatusSubresource(masqueradingRule).Build()
+	resolver := &fakeResolver{}
+	r := &MasqueradingRuleReconciler{
+		Client:                    c,
+		Scheme:                    c.Scheme(),
+		Recorder:                  record.NewFakeRecorder(100),
+		CorednsConfigMapNamespace: configMap.Namespace,
+		CorednsConfigMapName:      configMap.Name,
+		CorednsConfigMapKey:       "test.override",
+		Resolver:                  resolver,
+	}
+	owner := coredns.RewriteRuleOwner(string(masqueradingRule.UID), masqueradingRule.Namespace, masqueradingRule.Name)
+	otherOwner := coredns.RewriteRuleOwner("other-uid", masqueradingRule.Namespace, "other")
+
+	steps := []struct {
+		name        string
+		rules       string
+		resolverErr error
+		conflict    metav1.ConditionStatus
+		reconciling metav1.ConditionStatus
+		stalled     metav1.ConditionStatus
+	}{
+		{
+			name:        "conflict",
+			rules:       renderRewriteRules(t, [3]string{otherOwner, "www.example.io", "5.6.7.8"}),
+			conflict:    metav1.ConditionTrue,
+			reconciling: metav1.ConditionFalse,
+			stalled:     metav1.ConditionTrue,
+		},
+		{
+			name:        "error before conflict check",
+			rules:       "invalid",
+			conflict:    metav1.ConditionTrue,
+			reconciling: metav1.ConditionTrue,
+			stalled:     metav1.ConditionFalse,
+		},
+		{
+			name:        "transient error after conflict check",
+			rules:       renderRewriteRules(t, [3]string{owner, "www.example.io", "1.2.3.4"}),
+			resolverErr: fmt.Errorf("dns check failed"),
+			conflict:    metav1.ConditionFalse,
+			reconciling: metav1.ConditionTrue,
+			stalled:     metav1.ConditionFalse,
+		},
+	}
+	for _, step := range steps {
+		configMap.Data = map[string]string{r.CorednsConfigMapKey: step.rules}
+		if err := c.Update(context.TODO(), configMap); err != nil {
+			t.Fatalf("%s: got unexpected error: %s", step.name, err)
+		}
+		resolver.err = step.resolverErr
+
+		if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(masqueradingRule)}); err == nil {
+			t.Errorf("%s: expected error, got none", step.name)
+		}
+
+		if err := c.Get(context.TODO(), client.ObjectKeyFromObject(masqueradingRule), masqueradingRule); err != nil {
+			t.Fatalf("%s: got unexpected error: %s", step.name, err)
+		}
+		if masqueradingRule.Status.State != dnsv1alpha1.MasqueradingRuleStateError {
+			t.Errorf("%s: got state %s, want %s", step.name, masqueradingRule.Status.State, dnsv1alpha1.MasqueradingRuleStateError)
+		}
+		for conditionType, status := range map[string]metav1.ConditionStatus{
+			dnsv1alpha1.MasqueradingRuleConditionTypeConflict:    step.conflict,
+			dnsv1alpha1.MasqueradingRuleConditionTypeReconciling: step.reconciling,
+			dnsv1alpha1.MasqueradingRuleConditionTypeStalled:     step.stalled,
+		} {
+			if !apimeta.IsStatusConditionPresentAndEqual(masqueradingRule.Status.Conditions, conditionType, status) {
+				t.Errorf("%s: got condition %s %+v, want status %s", step.name, conditionType, apimeta.FindStatusCondition(masqueradingRule.Status.Conditions, conditionType), status)
+			}
+		}
+	}
+}
+
+func TestMasqueradingRuleReconcilerDeletionVerification(t *testing.T) {
+	masqueradingRule := &dnsv1alpha1.MasqueradingRule{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "default",
+			Name:              "test",
+			UID:               types.UID("test-uid"),
+			Finalizers:        []string{finalizer},
+			DeletionTimestamp: &metav1.Time{Time: time.Now()},
+		},
+		Spec:   dnsv1alpha1.MasqueradingRuleSpec{From: "*.bücher.example.io", To: "bücher.svc.cluster.local"},
+		Status: dnsv1alpha1.MasqueradingRuleStatus{State: dnsv1alpha1.MasqueradingRuleStateReady},
+	}
+	c := newFakeClientBuilder(t).WithObjects(masqueradingRule).WithStatusSubresource(masqueradingRule).Build()
+	resolver := &fakeResolver{active: true, err: fmt.Errorf("dns check failed")}
+	recorder := record.NewFakeRecorder(100)
+	r := &MasqueradingRuleReconciler{
+		Client:                      c,
+		Scheme:                      c.Scheme(),
+		Recorder:                    recorder,
+		CorednsConfigMapNamespace:   "kube-system",
+		CorednsConfigMapName:        "coredns-custom",
+		CorednsConfigMapKey:         "test.override",
+		Resolver:                    resolver,
+		VerifyDeletion:              true,
+		DeletionVerificationTimeout: time.Hour,
+	}
+
+	result, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(masqueradingRule)})
+	if err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	if result.RequeueAfter == 0 {
+		t.Errorf("expected requeue, got none")
+	}
+
+	expectedChecked := [][2]string{{"wildcard.xn--bcher-kva.example.io", "xn--bcher-kva.svc.cluster.local"}}
+	if !reflect.DeepEqual(resolver.checked, expectedChecked) {
+		t.Errorf("got checked records %v, want %v", resolver.checked, expectedChecked)
+	}
+
+	found := false
+	for len(recorder.Events) > 0 {
+		if event := <-recorder.Events; strings.HasPrefix(event, corev1.EventTypeWarning+" DeletionVerificationFailed ") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected warning event DeletionVerificationFailed, got none")
+	}
+
+	if err := c.Get(context.TODO(), client.ObjectKeyFromObject(masqueradingRule), masqueradingRule); err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	if masqueradingRule.Status.State != dnsv1alpha1.MasqueradingRuleStateDeleting {
+		t.Errorf("got state %s, want %s", masqueradingRule.Status.State, dnsv1alpha1.MasqueradingRuleStateDeleting)
+	}
+	if !slices.Contains(masqueradingRule.Finalizers, finalizer) {
+		t.Errorf("expected finalizer to be retained")
+	}
+}
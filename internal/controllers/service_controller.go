@@ -8,24 +8,29 @@ package controllers
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	"github.com/pkg/errors"
-	"github.com/sap/go-generics/maps"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	dnsv1alpha1 "github.com/sap/dns-masquerading-operator/api/v1alpha1"
 )
 
 // ServiceReconciler reconciles a Service object
 type ServiceReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme        *runtime.Scheme
+	Recorder      record.EventRecorder
+	Filter        *SourceFilter
+	ClusterDomain string
 }
 
 // Reconcile a service resource
@@ -43,38 +48,50 @@ func (r *ServiceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (re
 		return ctrl.Result{}, nil
 	}
 
-	if service.Annotations[annotationMasqueradeFrom] != "" && service.Annotations[annotationMasqueradeTo] == "" && service.Annotations[annotationMasqueradeToLegacy] == "" {
-		// TODO: make cluster domain (cluster.local) configurable, or auto-detect it somehow
-		service.Annotations[annotationMasqueradeTo] = fmt.Sprintf("%s.%s.svc.cluster.local", service.Name, service.Namespace)
+	// Clean up masquerading rules of objects not matching the filter (any longer)
+	if !r.Filter.Matches(service) {
+		log.V(1).Info("not matching filter; ignoring")
+		if err := manageDependentsWithTargets(ctx, r.Client, service, nil); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	targets, err := r.getTargets(ctx, service)
+	if err != nil {
+		return ctrl.Result{}, err
 	}
 
-	if err := manageDependents(ctx, r.Client, service, getHostsFromService(service)); err != nil {
+	if err := manageDependentsWithTargets(ctx, r.Client, service, targets); err != nil {
 		return ctrl.Result{}, err
 	}
 
 	return ctrl.Result{}, nil
 }
 
-// getHostsFromService extracts hosts of a service resource
-func getHostsFromService(service *corev1.Service) []string {
-	hosts := make(map[string]struct{})
-	if v, ok := service.Annotations[annotationMasqueradeFrom]; ok {
-		for _, host := range strings.Split(v, ",") {
-			hosts[host] = struct{}{}
-		}
+// GetTargets determines the masquerading rules (hosts mapped to rewrite targets) which would be maintained for given service;
+// in contrast to the reconciliation, invalid hosts and invalid entries of the masquerade-map annotation are not skipped, but returned as error
+func (r *ServiceReconciler) GetTargets(ctx context.Context, obj client.Object) (map[string]string, error) {
+	service, ok := obj.(*corev1.Service)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type %T", obj)
 	}
-	// services do not have a canonical way to specify external hostnames, so we just can apply some heuristic guess here ...
-	if v, ok := service.Annotations["external-dns.alpha.kubernetes.io/hostname"]; ok {
-		for _, host := range strings.Split(v, ",") {
-			hosts[host] = struct{}{}
-		}
+	if !r.Filter.Matches(service) {
+		return nil, nil
 	}
-	if v, ok := service.Annotations["dns.gardener.cloud/dnsnames"]; ok {
-		for _, host := range strings.Split(v, ",") {
-			hosts[host] = struct{}{}
-		}
+	if err := validateAnnotations(service); err != nil {
+		return nil, err
+	}
+	return r.getTargets(ctx, service)
+}
+
+// determine masquerading rules (hosts mapped to rewrite targets) of a service
+func (r *ServiceReconciler) getTargets(ctx context.Context, service *corev1.Service) (map[string]string, error) {
+	defaultTo := ""
+	if service.Annotations[annotationMasqueradeFrom] != "" {
+		defaultTo = getServiceAddress(service, r.ClusterDomain)
 	}
-	return maps.Keys(hosts)
+	return getTargets(ctx, r.Client, r.Recorder, service, getHosts(ctx, service), defaultTo)
 }
 
 // custom predicate to filter for service type
@@ -107,9 +124,59 @@ func annotationPredicate(key string) predicate.Predicate {
 	}
 }
 
+// custom predicate to filter for label being set
+func labelPredicate(key string) predicate.Predicate {
+	f := func(obj client.Object, key string) bool {
+		_, ok := obj.GetLabels()[key]
+		return ok
+	}
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return f(e.Object, key) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return f(e.ObjectNew, key) || f(e.ObjectOld, key) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return f(e.Object, key) },
+		GenericFunc: func(e event.GenericEvent) bool { return f(e.Object, key) },
+	}
+}
+
+// custom predicate to filter for changes of masquerading rules which are relevant for their owner (spec or state changes)
+func masqueradingRulePredicate() predicate.Predicate {
+	return predicate.Or(
+		predicate.GenerationChangedPredicate{},
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				oldMasqueradingRule, okOld := e.ObjectOld.(*dnsv1alpha1.MasqueradingRule)
+				newMasqueradingRule, okNew := e.ObjectNew.(*dnsv1alpha1.MasqueradingRule)
+				return okOld && okNew && (oldMasqueradingRule.Status.State != newMasqueradingRule.Status.State || oldMasqueradingRule.Status.ObservedGeneration != newMasqueradingRule.Status.ObservedGeneration)
+			},
+		},
+	)
+}
+
+// custom predicate to skip updates which only change the masquerading-status annotation (as written by the source controllers themselves)
+func masqueradingStatusPredicate() predicate.Predicate {
+	strip := func(obj client.Object) client.Object {
+		obj = obj.DeepCopyObject().(client.Object)
+		annotations := obj.GetAnnotations()
+		delete(annotations, annotationMasqueradingStatus)
+		if len(annotations) == 0 {
+			annotations = nil
+		}
+		obj.SetAnnotations(annotations)
+		obj.SetResourceVersion("")
+		obj.SetManagedFields(nil)
+		return obj
+	}
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return !equality.Semantic.DeepEqual(strip(e.ObjectOld), strip(e.ObjectNew))
+		},
+	}
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ServiceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&corev1.Service{}, builder.WithPredicates(predicate.Or(serviceTypePredicate(corev1.ServiceTypeLoadBalancer), annotationPredicate(annotationMasqueradeFrom)))).
+		For(&corev1.Service{}, builder.WithPredicates(r.Filter.Predicate(), masqueradingStatusPredicate(), predicate.Or(serviceTypePredicate(corev1.ServiceTypeLoadBalancer), annotationPredicate(annotationMasqueradeFrom)))).
+		Owns(&dnsv1alpha1.MasqueradingRule{}, builder.WithPredicates(masqueradingRulePredicate())).
 		Complete(r)
 }
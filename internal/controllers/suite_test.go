@@ -28,7 +28,9 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -46,6 +48,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	dnsv1alpha1 "github.com/sap/dns-masquerading-operator/api/v1alpha1"
+	dnsv1beta1 "github.com/sap/dns-masquerading-operator/api/v1beta1"
 	"github.com/sap/dns-masquerading-operator/internal/controllers"
 	"github.com/sap/dns-masquerading-operator/internal/coredns"
 	"github.com/sap/dns-masquerading-operator/internal/webhooks"
@@ -94,6 +97,7 @@ var threads sync.WaitGroup
 var tmpdir string
 var namespace string
 var resolver coredns.Resolver
+var masqueradingRuleReconciler *controllers.MasqueradingRuleReconciler
 
 var _ = BeforeSuite(func() {
 	var err error
@@ -104,10 +108,21 @@ var _ = BeforeSuite(func() {
 	tmpdir, err = os.MkdirTemp("", "")
 	Expect(err).NotTo(HaveOccurred())
 
+	By("populating scheme")
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(dnsv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(dnsv1beta1.AddToScheme(scheme))
+
 	By("bootstrapping test environment")
 	testEnv = &envtest.Environment{
+		// note: the scheme is used to enable the conversion webhook for convertible types
+		Scheme:            scheme,
 		CRDDirectoryPaths: []string{"../../crds"},
 		WebhookInstallOptions: envtest.WebhookInstallOptions{
+			MutatingWebhooks: []*admissionv1.MutatingWebhookConfiguration{
+				buildMutatingWebhookConfiguration(),
+			},
 			ValidatingWebhooks: []*admissionv1.ValidatingWebhookConfiguration{
 				buildValidatingWebhookConfiguration(),
 			},
@@ -125,11 +140,6 @@ var _ = BeforeSuite(func() {
 	Expect(err).NotTo(HaveOccurred())
 	fmt.Printf("A temporary kubeconfig for the envtest environment can be found here: %s/kubeconfig\n", tmpdir)
 
-	By("populating scheme")
-	scheme := runtime.NewScheme()
-	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
-	utilruntime.Must(dnsv1alpha1.AddToScheme(scheme))
-
 	By("initializing client")
 	cli, err = client.New(cfg, client.Options{Scheme: scheme})
 	Expect(err).NotTo(HaveOccurred())
@@ -224,7 +234,7 @@ var _ = BeforeSuite(func() {
 	}()
 
 	By("initializing resolver")
-	resolver = coredns.NewResolver(cli, cfg, false, coredns.Endpoint{Address: corednsAddress, Port: corednsPort})
+	resolver = coredns.NewResolver(cli, cfg, false, coredns.ResolverOptions{}, coredns.Endpoint{Address: corednsAddress, Port: corednsPort})
 
 	By("creating manager")
 	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
@@ -249,7 +259,7 @@ var _ = BeforeSuite(func() {
 	})
 	Expect(err).NotTo(HaveOccurred())
 
-	err = (&controllers.MasqueradingRuleReconciler{
+	masqueradingRuleReconciler = &controllers.MasqueradingRuleReconciler{
 		Client:                      mgr.GetClient(),
 		Scheme:                      mgr.GetScheme(),
 		Recorder:                    mgr.GetEventRecorderFor(controllerName),
@@ -258,32 +268,57 @@ var _ = BeforeSuite(func() {
 		CorednsConfigMapKey:         corednsConfigMapKey,
 		CorednsConfigMapUpdateDelay: 5 * time.Second,
 		Resolver:                    resolver,
-	}).SetupWithManager(mgr)
+		DeletionVerificationTimeout: 60 * time.Second,
+		ClusterDomain:               coredns.DefaultClusterDomain,
+	}
+	err = masqueradingRuleReconciler.SetupWithManager(mgr)
 	Expect(err).NotTo(HaveOccurred())
 
 	err = (&controllers.ServiceReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		Recorder:      mgr.GetEventRecorderFor(controllerName),
+		ClusterDomain: coredns.DefaultClusterDomain,
 	}).SetupWithManager(mgr)
 	Expect(err).NotTo(HaveOccurred())
 
-	err = (&controllers.IngressReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr)
+	ingressReconciler := &controllers.IngressReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		Recorder:      mgr.GetEventRecorderFor(controllerName),
+		ClusterDomain: coredns.DefaultClusterDomain,
+	}
+	err = ingressReconciler.SetupWithManager(mgr)
 	Expect(err).NotTo(HaveOccurred())
 
 	err = (&webhooks.MasqueradingRuleWebhook{
-		Log: ctrllog.Log.WithName("masqueradingrule-resource"),
+		Log:            ctrllog.Log.WithName("masqueradingrule-resource"),
+		Client:         mgr.GetCache(),
+		ConflictPolicy: webhooks.ConflictPolicyReject,
+		ClusterDomain:  coredns.DefaultClusterDomain,
+	}).SetupWebhookWithManager(mgr)
+	Expect(err).NotTo(HaveOccurred())
+
+	err = (&webhooks.SourceWebhook[*networkingv1.Ingress]{
+		Log:                       ctrllog.Log.WithName("ingress-resource"),
+		Client:                    mgr.GetClient(),
+		Targets:                   ingressReconciler.GetTargets,
+		CorednsConfigMapNamespace: corednsConfigMapNamespace,
+		CorednsConfigMapName:      corednsConfigMapName,
+		CorednsConfigMapKey:       corednsConfigMapKey,
 	}).SetupWebhookWithManager(mgr)
 	Expect(err).NotTo(HaveOccurred())
 
+	err = ctrl.NewWebhookManagedBy(mgr, &dnsv1beta1.MasqueradingRule{}).Complete()
+	Expect(err).NotTo(HaveOccurred())
+
 	By("starting dummy controller-manager")
 	threads.Add(1)
 	go func() {
 		defer threads.Done()
 		defer GinkgoRecover()
-		// since there is no controller-manager in envtest, we have to confirm foreground deletion finalizer explicitly
+		// since there is no controller-manager in envtest, we have to confirm foreground deletion finalizer explicitly,
+		// and to delete masquerading rules whose owner is gone (emulating the garbage collector)
 		for {
 			select {
 			case <-ctx.Done():
@@ -293,7 +328,21 @@ var _ = BeforeSuite(func() {
 				err := cli.List(context.Background(), masqueradingRuleList)
 				Expect(err).NotTo(HaveOccurred())
 				for _, masqueradingRule := range masqueradingRuleList.Items {
-					if !masqueradingRule.DeletionTimestamp.IsZero() {
+					if masqueradingRule.DeletionTimestamp.IsZero() {
+						for _, ownerRef := range masqueradingRule.OwnerReferences {
+							owner := &unstructured.Unstructured{}
+							owner.SetAPIVersion(ownerRef.APIVersion)
+							owner.SetKind(ownerRef.Kind)
+							err := cli.Get(context.Background(), types.NamespacedName{Namespace: masqueradingRule.Namespace, Name: ownerRef.Name}, owner)
+							if apierrors.IsNotFound(err) || err == nil && owner.GetUID() != ownerRef.UID {
+								err = cli.Delete(context.Background(), &masqueradingRule)
+								if apierrors.IsNotFound(err) {
+									err = nil
+								}
+							}
+							Expect(err).NotTo(HaveOccurred())
+						}
+					} else {
 						if controllerutil.RemoveFinalizer(&masqueradingRule, metav1.FinalizerDeleteDependents) {
 							err = cli.Update(context.Background(), &masqueradingRule)
 							if apierrors.IsNotFound(err) || apierrors.IsConflict(err) {
@@ -328,6 +377,7 @@ var _ = AfterSuite(func() {
 	By("tearing down the test environment")
 	cancel()
 	threads.Wait()
+	resolver.Close()
 	err := testEnv.Stop()
 	Expect(err).NotTo(HaveOccurred())
 	err = os.RemoveAll(tmpdir)
@@ -381,6 +431,127 @@ var _ = Describe("Create masquerading rules", func() {
 		validateRecord(mr.Spec.From, mr.Spec.To, 0)
 	})
 
+	It("should normalize source and target of a rule", func() {
+		mr := &dnsv1alpha1.MasqueradingRule{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:    namespace,
+				GenerateName: "test-",
+			},
+			Spec: dnsv1alpha1.MasqueradingRuleSpec{
+				From: strings.ToUpper(fromSpecific) + ".",
+				To:   "kubernetes.default",
+			},
+		}
+		err := cli.Create(ctx, mr)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mr.Spec.From).To(Equal(fromSpecific))
+		Expect(mr.Spec.To).To(Equal(toDnsName))
+		waitForMasqueradingRuleReady(mr)
+		validateRecord(mr.Spec.From, mr.Spec.To, 0)
+	})
+
+	It("should expand a short service name only when the target is set", func() {
+		serviceNamespace, err := createNamespace()
+		Expect(err).NotTo(HaveOccurred())
+		shortName := "test-service." + serviceNamespace
+		longName := shortName + ".svc.cluster.local"
+
+		mr := &dnsv1alpha1.MasqueradingRule{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:    namespace,
+				GenerateName: "test-",
+			},
+			Spec: dnsv1alpha1.MasqueradingRuleSpec{
+				From: fromSpecific,
+				To:   shortName,
+			},
+		}
+		err = cli.Create(ctx, mr)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mr.Spec.To).To(Equal(shortName))
+
+		service := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: serviceNamespace,
+				Name:      "test-service",
+			},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{{Port: 80}},
+			},
+		}
+		err = cli.Create(ctx, service)
+		Expect(err).NotTo(HaveOccurred())
+
+		// rules created after the service are expanded
+		Eventually(func(g Gomega) {
+			newMr := &dnsv1alpha1.MasqueradingRule{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:    namespace,
+					GenerateName: "test-",
+				},
+				Spec: dnsv1alpha1.MasqueradingRuleSpec{
+					From: "new." + fromSpecific,
+					To:   shortName,
+				},
+			}
+			err := cli.Create(ctx, newMr, client.DryRunAll)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(newMr.Spec.To).To(Equal(longName))
+		}, "10s", "500ms").Should(Succeed())
+
+		// whereas the target of the existing rule is kept on updates not touching the target
+		Eventually(func(g Gomega) {
+			err := cli.Get(ctx, types.NamespacedName{Namespace: mr.Namespace, Name: mr.Name}, mr)
+			g.Expect(err).NotTo(HaveOccurred())
+			mr.Labels = map[string]string{"test": "true"}
+			err = cli.Update(ctx, mr)
+			g.Expect(err).NotTo(HaveOccurred())
+		}, "10s", "500ms").Should(Succeed())
+		Expect(mr.Spec.To).To(Equal(shortName))
+	})
+
+	It("should set kstatus compatible conditions on a ready rule", func() {
+		mr := &dnsv1alpha1.MasqueradingRule{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:    namespace,
+				GenerateName: "test-",
+			},
+			Spec: dnsv1alpha1.MasqueradingRuleSpec{
+				From: fromSpecific,
+				To:   toDnsName,
+			},
+		}
+		err := cli.Create(ctx, mr)
+		Expect(err).NotTo(HaveOccurred())
+		waitForMasqueradingRuleReady(mr)
+		Expect(meta.IsStatusConditionTrue(mr.Status.Conditions, dnsv1alpha1.MasqueradingRuleConditionTypeReady)).To(BeTrue())
+		Expect(meta.IsStatusConditionFalse(mr.Status.Conditions, dnsv1alpha1.MasqueradingRuleConditionTypeReconciling)).To(BeTrue())
+		Expect(meta.IsStatusConditionFalse(mr.Status.Conditions, dnsv1alpha1.MasqueradingRuleConditionTypeStalled)).To(BeTrue())
+		Expect(meta.IsStatusConditionFalse(mr.Status.Conditions, dnsv1alpha1.MasqueradingRuleConditionTypeConflict)).To(BeTrue())
+		for _, condition := range mr.Status.Conditions {
+			Expect(condition.ObservedGeneration).To(Equal(mr.Generation))
+		}
+	})
+
+	It("should create a rule with internationalized source", func() {
+		mr := &dnsv1alpha1.MasqueradingRule{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:    namespace,
+				GenerateName: "test-",
+			},
+			Spec: dnsv1alpha1.MasqueradingRuleSpec{
+				From: "bücher." + fromSpecific,
+				To:   toDnsName,
+			},
+		}
+		err := cli.Create(ctx, mr)
+		Expect(err).NotTo(HaveOccurred())
+		waitForMasqueradingRuleReady(mr)
+		Expect(mr.Status.From).To(Equal("xn--bcher-kva." + fromSpecific))
+		Expect(mr.Status.FromUnicode).To(Equal("bücher." + fromSpecific))
+		validateRecord(mr.Status.From, mr.Spec.To, 0)
+	})
+
 	It("should reject a rule with wildcard source and IP target", func() {
 		mr := &dnsv1alpha1.MasqueradingRule{
 			ObjectMeta: metav1.ObjectMeta{
@@ -398,6 +569,37 @@ var _ = Describe("Create masquerading rules", func() {
 		}
 	})
 
+	It("should reject a rule clashing with an existing rule", func() {
+		mr := &dnsv1alpha1.MasqueradingRule{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:    namespace,
+				GenerateName: "test-",
+			},
+			Spec: dnsv1alpha1.MasqueradingRuleSpec{
+				From: fmt.Sprintf("*.%s", fromSpecific),
+				To:   toDnsName,
+			},
+		}
+		err := cli.Create(ctx, mr)
+		Expect(err).NotTo(HaveOccurred())
+		waitForMasqueradingRuleReady(mr)
+
+		Eventually(func() bool {
+			mr := &dnsv1alpha1.MasqueradingRule{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:    namespace,
+					GenerateName: "test-",
+				},
+				Spec: dnsv1alpha1.MasqueradingRuleSpec{
+					From: fmt.Sprintf("%s.%s", randomString(10), fromSpecific),
+					To:   toIpAddress,
+				},
+			}
+			err := cli.Create(ctx, mr)
+			return apierrors.IsForbidden(err)
+		}, "10s", "500ms").Should(BeTrue())
+	})
+
 	It("should create a rule with wildcard source and DNS name target", func() {
 		mr := &dnsv1alpha1.MasqueradingRule{
 			ObjectMeta: metav1.ObjectMeta{
@@ -416,6 +618,42 @@ var _ = Describe("Create masquerading rules", func() {
 	})
 })
 
+var _ = Describe("Convert masquerading rules", func() {
+	It("should serve a v1beta1 rule as v1alpha1 and vice versa", func() {
+		ttl := int32(30)
+		from := fmt.Sprintf("%s.%s", randomString(10), randomString(5))
+		mr := &dnsv1beta1.MasqueradingRule{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:    namespace,
+				GenerateName: "test-",
+			},
+			Spec: dnsv1beta1.MasqueradingRuleSpec{
+				From:      from,
+				MatchType: dnsv1beta1.MatchTypeWildcard,
+				Targets:   []string{"kubernetes.default.svc.cluster.local"},
+				TTL:       &ttl,
+			},
+		}
+		err := cli.Create(ctx, mr)
+		Expect(err).NotTo(HaveOccurred())
+
+		hub := &dnsv1alpha1.MasqueradingRule{}
+		err = cli.Get(ctx, types.NamespacedName{Namespace: mr.Namespace, Name: mr.Name}, hub)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hub.Spec.From).To(Equal("*." + from))
+		Expect(hub.Spec.To).To(Equal("kubernetes.default.svc.cluster.local"))
+		waitForMasqueradingRuleReady(hub)
+
+		err = cli.Get(ctx, types.NamespacedName{Namespace: mr.Namespace, Name: mr.Name}, mr)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mr.Spec.From).To(Equal(from))
+		Expect(mr.Spec.MatchType).To(Equal(dnsv1beta1.MatchTypeWildcard))
+		Expect(mr.Spec.TTL).To(Equal(&ttl))
+		Expect(mr.Annotations).NotTo(HaveKey(dnsv1beta1.AnnotationSpec))
+		Expect(meta.IsStatusConditionTrue(mr.Status.Conditions, dnsv1alpha1.MasqueradingRuleConditionTypeReady)).To(BeTrue())
+	})
+})
+
 var _ = Describe("Update masquerading rules", func() {
 	var fromBefore string
 	var fromAfter string
@@ -495,6 +733,46 @@ var _ = Describe("Delete masquerading rules", func() {
 	})
 })
 
+var _ = Describe("Delete masquerading rules with deletion verification", func() {
+	BeforeEach(func() {
+		// note: tests are run serially, so no other rules are being deleted in the meantime
+		masqueradingRuleReconciler.VerifyDeletion = true
+		DeferCleanup(func() {
+			masqueradingRuleReconciler.VerifyDeletion = false
+		})
+	})
+
+	DescribeTable("should delete the rule only after it was removed from DNS",
+		func(to string) {
+			masqueradingRule := &dnsv1alpha1.MasqueradingRule{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:    namespace,
+					GenerateName: "test-",
+				},
+				Spec: dnsv1alpha1.MasqueradingRuleSpec{
+					From: fmt.Sprintf("%s.%s", randomString(10), randomString(5)),
+					To:   to,
+				},
+			}
+			err := cli.Create(ctx, masqueradingRule)
+			Expect(err).NotTo(HaveOccurred())
+			waitForMasqueradingRuleReady(masqueradingRule)
+			validateRecord(masqueradingRule.Spec.From, masqueradingRule.Spec.To, 0)
+
+			err = cli.Delete(ctx, masqueradingRule)
+			Expect(err).NotTo(HaveOccurred())
+			waitForMasqueradingRuleGone(masqueradingRule)
+			// deletion is verified by the operator, so the record must be gone immediately
+			removed, err := resolver.CheckRecordRemoved(ctx, masqueradingRule.Spec.From, masqueradingRule.Spec.To)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(removed).To(BeTrue())
+			validateRecord(masqueradingRule.Spec.From, "", 0)
+		},
+		Entry("with an IP address target", "10.1.2.3"),
+		Entry("with a DNS name target", "kubernetes.default.svc.cluster.local"),
+	)
+})
+
 var _ = Describe("Ingress tests", func() {
 	var host1 string
 	var host2 string
@@ -553,6 +831,141 @@ var _ = Describe("Ingress tests", func() {
 		ensureMasqueradingRulesForIngress(ingress)
 	})
 
+	It("should replace masquerading rules created by earlier versions of the operator", func() {
+		ingress := &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:    namespace,
+				GenerateName: "test-",
+				Annotations: map[string]string{
+					"dns.cs.sap.com/masquerade-to-auto": "false",
+				},
+			},
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{
+					{
+						Host: host1,
+					},
+				},
+			},
+		}
+		err := cli.Create(ctx, ingress)
+		Expect(err).NotTo(HaveOccurred())
+		ensureMasqueradingRulesForIngress(ingress)
+
+		// rule as created by earlier versions of the operator (generated name, controller labels, but no owner reference)
+		labels := map[string]string{
+			"dns.cs.sap.com/controller-group":   networkingv1.GroupName,
+			"dns.cs.sap.com/controller-version": networkingv1.SchemeGroupVersion.Version,
+			"dns.cs.sap.com/controller-kind":    "Ingress",
+			"dns.cs.sap.com/controller-name":    ingress.Name,
+			"dns.cs.sap.com/controller-uid":     string(ingress.UID),
+		}
+		baselineRule := &dnsv1alpha1.MasqueradingRule{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:    namespace,
+				GenerateName: ingress.Name + "-",
+				Labels:       labels,
+			},
+			Spec: dnsv1alpha1.MasqueradingRuleSpec{
+				From: host1,
+				To:   "kubernetes.default.svc.cluster.local",
+			},
+		}
+		err = cli.Create(ctx, baselineRule)
+		Expect(err).NotTo(HaveOccurred())
+		waitForMasqueradingRuleReady(baselineRule)
+
+		// a rule of another resource with the same source is rejected, whereas a rule of the same resource is admitted
+		foreignRule := &dnsv1alpha1.MasqueradingRule{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      "foreign-" + randomString(5),
+				Labels:    map[string]string{"dns.cs.sap.com/controller-uid": "foreign"},
+			},
+			Spec: baselineRule.Spec,
+		}
+		err = cli.Create(ctx, foreignRule, client.DryRunAll)
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		replacingRule := &dnsv1alpha1.MasqueradingRule{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      "replacing-" + randomString(5),
+				Labels:    labels,
+			},
+			Spec: baselineRule.Spec,
+		}
+		err = cli.Create(ctx, replacingRule, client.DryRunAll)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = cli.Get(ctx, types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}, ingress)
+		Expect(err).NotTo(HaveOccurred())
+		ingress.Annotations = map[string]string{
+			"dns.cs.sap.com/masquerade-to": "kubernetes.default.svc.cluster.local",
+		}
+		err = cli.Update(ctx, ingress)
+		Expect(err).NotTo(HaveOccurred())
+		ensureMasqueradingRulesForIngress(ingress)
+		waitForMasqueradingRuleGone(baselineRule)
+	})
+
+	It("should reject an ingress with an invalid masquerading target", func() {
+		ingress := &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:    namespace,
+				GenerateName: "test-",
+				Annotations: map[string]string{
+					"dns.cs.sap.com/masquerade-to": "invalid..target",
+				},
+			},
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{
+					{
+						Host: host1,
+					},
+				},
+			},
+		}
+
+		err := cli.Create(ctx, ingress)
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+	})
+
+	It("should reject an ingress conflicting with an existing masquerading rule", func() {
+		mr := &dnsv1alpha1.MasqueradingRule{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:    namespace,
+				GenerateName: "test-",
+			},
+			Spec: dnsv1alpha1.MasqueradingRuleSpec{
+				From: host1,
+				To:   "kubernetes.default.svc.cluster.local",
+			},
+		}
+		err := cli.Create(ctx, mr)
+		Expect(err).NotTo(HaveOccurred())
+		waitForMasqueradingRuleReady(mr)
+
+		ingress := &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:    namespace,
+				GenerateName: "test-",
+				Annotations: map[string]string{
+					"dns.cs.sap.com/masquerade-to": "kubernetes.default.svc.cluster.local",
+				},
+			},
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{
+					{
+						Host: host1,
+					},
+				},
+			},
+		}
+
+		err = cli.Create(ctx, ingress)
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+	})
+
 })
 
 func waitForMasqueradingRuleReady(masqueradingRule *dnsv1alpha1.MasqueradingRule) {
@@ -608,6 +1021,7 @@ func ensureMasqueradingRulesForIngress(ingress *networkingv1.Ingress) {
 		if to, ok := ingress.Annotations["dns.cs.sap.com/masquerade-to"]; ok && ingress.DeletionTimestamp.IsZero() {
 			g.Expect(masqueradingRuleList.Items).To(HaveLen(len(ingress.Spec.Rules)))
 			for _, masqueradingRule := range masqueradingRuleList.Items {
+				g.Expect(metav1.IsControlledBy(&masqueradingRule, ingress)).To(BeTrue())
 				g.Expect(masqueradingRule.Spec.From).To(BeElementOf(slices.Collect(ingress.Spec.Rules, func(rule networkingv1.IngressRule) string { return rule.Host })))
 				g.Expect(masqueradingRule.Spec.To).To(Equal(to))
 			}
@@ -626,6 +1040,36 @@ func createNamespace() (string, error) {
 	return namespace.Name, nil
 }
 
+// assemble mutatingwebhookconfiguration descriptor
+func buildMutatingWebhookConfiguration() *admissionv1.MutatingWebhookConfiguration {
+	return &admissionv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mutate-masqueradingrule",
+		},
+		Webhooks: []admissionv1.MutatingWebhook{{
+			Name:                    "mutate-masqueradingrule.test.local",
+			AdmissionReviewVersions: []string{"v1"},
+			ClientConfig: admissionv1.WebhookClientConfig{
+				Service: &admissionv1.ServiceReference{
+					Path: &[]string{fmt.Sprintf("/mutate-%s-%s-%s", strings.ReplaceAll(dnsv1alpha1.GroupVersion.Group, ".", "-"), dnsv1alpha1.GroupVersion.Version, "masqueradingrule")}[0],
+				},
+			},
+			Rules: []admissionv1.RuleWithOperations{{
+				Operations: []admissionv1.OperationType{
+					admissionv1.Create,
+					admissionv1.Update,
+				},
+				Rule: admissionv1.Rule{
+					APIGroups:   []string{dnsv1alpha1.GroupVersion.Group},
+					APIVersions: []string{dnsv1alpha1.GroupVersion.Version},
+					Resources:   []string{"masqueradingrules"},
+				},
+			}},
+			SideEffects: &[]admissionv1.SideEffectClass{admissionv1.SideEffectClassNone}[0],
+		}},
+	}
+}
+
 // assemble validatingwebhookconfiguration descriptor
 func buildValidatingWebhookConfiguration() *admissionv1.ValidatingWebhookConfiguration {
 	return &admissionv1.ValidatingWebhookConfiguration{
@@ -653,6 +1097,26 @@ func buildValidatingWebhookConfiguration() *admissionv1.ValidatingWebhookConfigu
 				},
 			}},
 			SideEffects: &[]admissionv1.SideEffectClass{admissionv1.SideEffectClassNone}[0],
+		}, {
+			Name:                    "validate-ingress.test.local",
+			AdmissionReviewVersions: []string{"v1"},
+			ClientConfig: admissionv1.WebhookClientConfig{
+				Service: &admissionv1.ServiceReference{
+					Path: &[]string{fmt.Sprintf("/validate-%s-%s-%s", strings.ReplaceAll(networkingv1.GroupName, ".", "-"), networkingv1.SchemeGroupVersion.Version, "ingress")}[0],
+				},
+			},
+			Rules: []admissionv1.RuleWithOperations{{
+				Operations: []admissionv1.OperationType{
+					admissionv1.Create,
+					admissionv1.Update,
+				},
+				Rule: admissionv1.Rule{
+					APIGroups:   []string{networkingv1.GroupName},
+					APIVersions: []string{networkingv1.SchemeGroupVersion.Version},
+					Resources:   []string{"ingresses"},
+				},
+			}},
+			SideEffects: &[]admissionv1.SideEffectClass{admissionv1.SideEffectClassNone}[0],
 		}},
 	}
 }
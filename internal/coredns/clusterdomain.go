@@ -0,0 +1,105 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package coredns
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/sap/dns-masquerading-operator/internal/dnsutil"
+)
+
+// Cluster domain to be used if auto-detection fails
+const DefaultClusterDomain = "cluster.local"
+
+// Locations examined by DetectClusterDomain(); these are fixed (matching the CoreDNS deployment of kubeadm and most distributions,
+// and the kube-dns service also used by the resolver); on clusters deviating from that, the cluster domain has to be specified explicitly
+const (
+	corednsNamespace     = "kube-system"
+	corednsConfigMapName = "coredns"
+	corednsConfigMapKey  = "Corefile"
+	kubeDnsServiceName   = "kube-dns"
+)
+
+// Detect the cluster domain of the target cluster; first, the zones of the kubernetes plugin in the Corefile
+// (as found in the kube-system/coredns configmap) are examined; if that does not yield a result, and if running in-cluster,
+// the cluster ip of the kube-system/kube-dns service is reverse-resolved (expecting kube-dns.kube-system.svc.<cluster domain>);
+// returns an empty string if the cluster domain could not be detected.
+func DetectClusterDomain(ctx context.Context, reader client.Reader, inCluster bool) (string, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := reader.Get(ctx, types.NamespacedName{Namespace: corednsNamespace, Name: corednsConfigMapName}, configMap); err != nil {
+		if err := client.IgnoreNotFound(err); err != nil {
+			return "", errors.Wrapf(err, "error reading configmap %s/%s", corednsNamespace, corednsConfigMapName)
+		}
+	} else if clusterDomain := parseCorefileClusterDomain(configMap.Data[corednsConfigMapKey]); clusterDomain != "" {
+		return clusterDomain, nil
+	}
+
+	if !inCluster {
+		return "", nil
+	}
+
+	service := &corev1.Service{}
+	if err := reader.Get(ctx, types.NamespacedName{Namespace: corednsNamespace, Name: kubeDnsServiceName}, service); err != nil {
+		if err := client.IgnoreNotFound(err); err != nil {
+			return "", errors.Wrapf(err, "error reading service %s/%s", corednsNamespace, kubeDnsServiceName)
+		}
+		return "", nil
+	}
+	if net.ParseIP(service.Spec.ClusterIP) == nil {
+		return "", nil
+	}
+	reverseName, err := dns.ReverseAddr(service.Spec.ClusterIP)
+	if err != nil {
+		return "", errors.Wrapf(err, "error reverse-resolving cluster ip of service %s/%s", corednsNamespace, kubeDnsServiceName)
+	}
+	result, err := dnsutil.NewClient(dnsutil.NetworkUDP, 0, 0).Query(ctx, reverseName, dns.TypePTR, service.Spec.ClusterIP, 53)
+	if err != nil {
+		return "", errors.Wrapf(err, "error reverse-resolving cluster ip of service %s/%s", corednsNamespace, kubeDnsServiceName)
+	}
+	prefix := kubeDnsServiceName + "." + corednsNamespace + ".svc."
+	for _, record := range result.Records {
+		if record.Type == "PTR" && strings.HasPrefix(record.Value, prefix) {
+			return strings.TrimPrefix(record.Value, prefix), nil
+		}
+	}
+	return "", nil
+}
+
+// extract the cluster domain from a Corefile; that is, the first zone of the kubernetes plugin which is not a reverse zone;
+// returns an empty string if there is no such zone
+func parseCorefileClusterDomain(corefile string) string {
+	scanner := bufio.NewScanner(strings.NewReader(corefile))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] != "kubernetes" {
+			continue
+		}
+		for _, zone := range fields[1:] {
+			if zone == "{" {
+				break
+			}
+			zone = strings.ToLower(strings.TrimSuffix(zone, "."))
+			if zone == "" || strings.HasSuffix(zone, "in-addr.arpa") || strings.HasSuffix(zone, "ip6.arpa") {
+				continue
+			}
+			return zone
+		}
+	}
+	return ""
+}
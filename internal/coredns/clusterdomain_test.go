@@ -0,0 +1,77 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package coredns
+
+import (
+	"testing"
+)
+
+func TestParseCorefileClusterDomain(t *testing.T) {
+	tests := []struct {
+		name     string
+		corefile string
+		expected string
+	}{
+		{
+			name: "default",
+			corefile: `
+.:53 {
+    errors
+    health
+    kubernetes cluster.local in-addr.arpa ip6.arpa {
+        pods insecure
+        fallthrough in-addr.arpa ip6.arpa
+    }
+    forward . /etc/resolv.conf
+}
+`,
+			expected: "cluster.local",
+		},
+		{
+			name: "custom domain, reverse zones first, trailing dot",
+			corefile: `
+.:53 {
+    kubernetes in-addr.arpa ip6.arpa My.Domain. {
+        pods insecure
+    }
+}
+`,
+			expected: "my.domain",
+		},
+		{
+			name: "commented out",
+			corefile: `
+.:53 {
+    # kubernetes cluster.local
+    kubernetes other.local
+}
+`,
+			expected: "other.local",
+		},
+		{
+			name: "no zones",
+			corefile: `
+cluster.local:53 {
+    kubernetes {
+        pods insecure
+    }
+}
+`,
+			expected: "",
+		},
+		{
+			name:     "empty",
+			corefile: "",
+			expected: "",
+		},
+	}
+
+	for _, test := range tests {
+		if actual := parseCorefileClusterDomain(test.corefile); actual != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.name, test.expected, actual)
+		}
+	}
+}
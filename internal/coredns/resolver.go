@@ -8,8 +8,12 @@ package coredns
 import (
 	"context"
 	"fmt"
+	"net"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
+	"github.com/miekg/dns"
 	"github.com/sap/go-generics/pairs"
 	"github.com/sap/go-generics/slices"
 
@@ -25,13 +29,22 @@ import (
 
 // Resolver interface
 type Resolver interface {
-	// Check that the DNS resolution of host and expectedResult return the same address(es);
+	// Check that the DNS resolution of host and expectedResult return the same address(es)
+	// (or, depending on the verification mode, that host is actually rewritten to expectedResult);
 	// host must be a real DNS name, and must not be a wildcard name;
 	// expectedResult may be a DNS name, an IP address, or empty, which means that the resolution of host
 	// should not return any results, in order to make the check successful;
 	// the boolean return value indicates success or failure of the check, the error return value
 	// should be used to raise technical errors while performing the DNS resolution.
 	CheckRecord(ctx context.Context, host string, expectedResult string) (bool, error)
+	// Check that the DNS resolution of host no longer reflects a (removed) rewrite to formerResult; that is,
+	// no nameserver returns evidence of the rewrite (as checked in VerificationModeRewrite, regardless of the
+	// configured verification mode, because comparing addresses cannot tell a removed rewrite apart from a host
+	// which resolves to the same addresses anyway, e.g. upstream or through the hosts plugin); host must be a real DNS name, and must not be a wildcard name; formerResult may be a DNS name or an IP address;
+	// return values have the same meaning as for CheckRecord().
+	CheckRecordRemoved(ctx context.Context, host string, formerResult string) (bool, error)
+	// Release resources held by the resolver (such as pooled port-forwards); the resolver must not be used afterwards.
+	Close()
 }
 
 // Endpoint representation for a namesever to be used be the resolver;
@@ -47,29 +60,94 @@ type Endpoint struct {
 	Name      string
 }
 
+// Verification mode, controlling how the default resolver decides whether a record is active
+type VerificationMode string
+
+const (
+	// Consider a record active if host and expected result resolve to the same (non-empty) set of addresses
+	VerificationModeAddresses VerificationMode = "addresses"
+	// Consider a record active if the answer for host shows that it was served by the rewrite resp. hosts rule
+	// (that is, it contains the expected name or its CNAME chain, or it is authoritative and contains the same
+	// addresses as the authoritative answer for the expected result); for IP address targets, an authoritative
+	// answer containing exactly that address is required
+	VerificationModeRewrite VerificationMode = "rewrite"
+)
+
+// Options for the default resolver
+type ResolverOptions struct {
+	// Verification mode; defaults to VerificationModeAddresses
+	VerificationMode VerificationMode
+	// Timeout for a single DNS query attempt; defaults to dnsutil.DefaultTimeout
+	QueryTimeout time.Duration
+	// Number of attempts per DNS query; defaults to dnsutil.DefaultAttempts
+	QueryAttempts int
+	// Overall deadline for a single CheckRecord() call (including port-forward startup); zero means no deadline
+	// (besides the deadline of the context passed to CheckRecord())
+	CheckTimeout time.Duration
+}
+
 type resolver struct {
 	client     client.Client
 	restConfig *rest.Config
 	inCluster  bool
 	endpoints  []Endpoint
+	udpClient  *dnsutil.Client
+	tcpClient  *dnsutil.Client
+	mode       VerificationMode
+	timeout    time.Duration
+	forwards   *portforward.Pool
 }
 
 // Create new default resolver; the inCluster parameter has to be set to true if this operator is running inside the target cluster;
 // if at least one endpoint is supplied, the specified endpoint(s) will be used for DNS queries;
 // otherwise, the pod endpoints of the kube-system/kube-dns service will be used.
-func NewResolver(client client.Client, restConfig *rest.Config, inCluster bool, endpoints ...Endpoint) Resolver {
+// DNS queries are sent via UDP (falling back to TCP for truncated responses); queries through port-forwards use TCP only.
+// When running outside the cluster, port-forwards to in-cluster nameservers are pooled, that is, kept alive across checks.
+func NewResolver(client client.Client, restConfig *rest.Config, inCluster bool, options ResolverOptions, endpoints ...Endpoint) Resolver {
+	var forwards *portforward.Pool
+	if !inCluster {
+		forwards = portforward.NewPool(restConfig, "127.0.0.1")
+	}
 	return &resolver{
 		client:     client,
 		restConfig: restConfig,
 		inCluster:  inCluster,
 		endpoints:  endpoints,
+		udpClient:  dnsutil.NewClient(dnsutil.NetworkUDP, options.QueryTimeout, options.QueryAttempts),
+		tcpClient:  dnsutil.NewClient(dnsutil.NetworkTCP, options.QueryTimeout, options.QueryAttempts),
+		mode:       options.VerificationMode,
+		timeout:    options.CheckTimeout,
+		forwards:   forwards,
 	}
 }
 
 // Check record (see Resolver interface)
 func (r *resolver) CheckRecord(ctx context.Context, host string, expectedResult string) (bool, error) {
+	return r.checkEndpoints(ctx, host, expectedResult, r.mode, false)
+}
+
+// Check record removal (see Resolver interface)
+func (r *resolver) CheckRecordRemoved(ctx context.Context, host string, formerResult string) (bool, error) {
+	return r.checkEndpoints(ctx, host, formerResult, VerificationModeRewrite, true)
+}
+
+// Close resolver (see Resolver interface)
+func (r *resolver) Close() {
+	if r.forwards != nil {
+		r.forwards.Close()
+	}
+}
+
+// check record on all endpoints, using the given verification mode; succeeds if the check succeeds (or, if negate is true, fails) on every endpoint
+func (r *resolver) checkEndpoints(ctx context.Context, host string, expectedResult string, mode VerificationMode, negate bool) (bool, error) {
 	log := ctrl.LoggerFrom(ctx)
 
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
 	endpoints := r.endpoints
 	if len(endpoints) == 0 {
 		clusterEndpoints, err := discoverEndpoints(ctx, r.client)
@@ -77,6 +155,14 @@ func (r *resolver) CheckRecord(ctx context.Context, host string, expectedResult
 			return false, err
 		}
 		endpoints = clusterEndpoints
+		if r.forwards != nil {
+			// drop port-forwards to pods which are no longer endpoints of the nameserver service
+			var targets []portforward.Target
+			for _, endpoint := range endpoints {
+				targets = append(targets, portforward.Target{Namespace: endpoint.Namespace, Name: endpoint.Name, Port: endpoint.Port})
+			}
+			r.forwards.Retain(targets)
+		}
 	}
 
 	results := make([]chan *pairs.Pair[bool, error], len(endpoints))
@@ -85,44 +171,22 @@ func (r *resolver) CheckRecord(ctx context.Context, host string, expectedResult
 		go func(i int) {
 			if endpoints[i].InCluster && !r.inCluster {
 				log.V(1).Info("starting out-of-cluster lookup", "host", host, "serverNamespace", endpoints[i].Namespace, "serverName", endpoints[i].Name, "serverPort", endpoints[i].Port)
-				localhost := "127.0.0.1"
-				portforward := portforward.New(r.restConfig, localhost, 0, endpoints[i].Namespace, endpoints[i].Name, endpoints[i].Port)
-				if err := portforward.Start(); err != nil {
+				target := portforward.Target{Namespace: endpoints[i].Namespace, Name: endpoints[i].Name, Port: endpoints[i].Port}
+				portforward, err := r.forwards.Get(ctx, target)
+				if err != nil {
 					results[i] <- pairs.New(false, err)
 					return
 				}
-				defer portforward.Stop()
-				localport := portforward.LocalPort()
-				var merr error
-				addresses, err := dnsutil.Lookup(host, localhost, localport)
-				if err != nil {
-					merr = multierror.Append(merr, err)
-				}
-				if expectedResult == "" {
-					results[i] <- pairs.New(merr == nil && len(addresses) == 0, merr)
-				} else {
-					expectedAddresses, err := dnsutil.Lookup(expectedResult, localhost, localport)
-					if err != nil {
-						merr = multierror.Append(merr, err)
-					}
-					results[i] <- pairs.New(merr == nil && len(addresses) > 0 && slices.Equal(addresses, expectedAddresses), merr)
+				// port-forwards support TCP only
+				active, err := check(ctx, mode, r.tcpClient, portforward.LocalAddress(), portforward.LocalPort(), host, expectedResult)
+				if err != nil && ctx.Err() == nil {
+					// enforce reconnect on next usage, in case the forward is broken without having noticed it
+					r.forwards.Invalidate(target, portforward)
 				}
+				results[i] <- pairs.New(active, err)
 			} else {
 				log.V(1).Info("starting lookup", "host", host, "serverAddress", endpoints[i].Address, "serverPort", endpoints[i].Port)
-				var merr error
-				addresses, err := dnsutil.Lookup(host, endpoints[i].Address, endpoints[i].Port)
-				if err != nil {
-					merr = multierror.Append(merr, err)
-				}
-				if expectedResult == "" {
-					results[i] <- pairs.New(merr == nil && len(addresses) == 0, merr)
-				} else {
-					expectedAddresses, err := dnsutil.Lookup(expectedResult, endpoints[i].Address, endpoints[i].Port)
-					if err != nil {
-						merr = multierror.Append(merr, err)
-					}
-					results[i] <- pairs.New(merr == nil && len(addresses) > 0 && slices.Equal(addresses, expectedAddresses), merr)
-				}
+				results[i] <- pairs.New(check(ctx, mode, r.udpClient, endpoints[i].Address, endpoints[i].Port, host, expectedResult))
 			}
 		}(i)
 	}
@@ -136,7 +200,7 @@ func (r *resolver) CheckRecord(ctx context.Context, host string, expectedResult
 			merr = multierror.Append(merr, p.Y)
 			continue
 		}
-		if !p.X {
+		if p.X == negate {
 			active = false
 		}
 	}
@@ -144,6 +208,101 @@ func (r *resolver) CheckRecord(ctx context.Context, host string, expectedResult
 	return active, merr
 }
 
+// check record on a single nameserver, according to the given verification mode
+func check(ctx context.Context, mode VerificationMode, dnsClient *dnsutil.Client, serverAddress string, serverPort uint16, host string, expectedResult string) (bool, error) {
+	if mode == VerificationModeRewrite {
+		return checkRewrite(ctx, dnsClient, serverAddress, serverPort, host, expectedResult)
+	}
+	return checkRecord(ctx, dnsClient, serverAddress, serverPort, host, expectedResult)
+}
+
+// check record on a single nameserver
+func checkRecord(ctx context.Context, dnsClient *dnsutil.Client, serverAddress string, serverPort uint16, host string, expectedResult string) (bool, error) {
+	result, err := dnsClient.Lookup(ctx, host, serverAddress, serverPort)
+	if err != nil {
+		return false, err
+	}
+	addresses := result.Addresses()
+	if expectedResult == "" {
+		return len(addresses) == 0, nil
+	}
+	expected, err := dnsClient.Lookup(ctx, expectedResult, serverAddress, serverPort)
+	if err != nil {
+		return false, err
+	}
+	return len(addresses) > 0 && slices.Equal(addresses, expected.Addresses()), nil
+}
+
+// check on a single nameserver that host is rewritten to expectedResult
+func checkRewrite(ctx context.Context, dnsClient *dnsutil.Client, serverAddress string, serverPort uint16, host string, expectedResult string) (bool, error) {
+	if expectedResult == "" {
+		return checkRecord(ctx, dnsClient, serverAddress, serverPort, host, expectedResult)
+	}
+
+	if ip := net.ParseIP(expectedResult); ip != nil {
+		// IP address targets are served by the hosts plugin, which answers authoritatively
+		qtype := dns.TypeA
+		if ip.To4() == nil {
+			qtype = dns.TypeAAAA
+		}
+		result, err := dnsClient.Query(ctx, host, qtype, serverAddress, serverPort)
+		if err != nil {
+			return false, err
+		}
+		return result.Authoritative && slices.Equal(result.Addresses(), []string{ip.String()}), nil
+	}
+
+	result, err := dnsClient.Lookup(ctx, host, serverAddress, serverPort)
+	if err != nil {
+		return false, err
+	}
+	if len(result.Addresses()) == 0 {
+		return false, nil
+	}
+	expected, err := dnsClient.Lookup(ctx, expectedResult, serverAddress, serverPort)
+	if err != nil {
+		return false, err
+	}
+
+	// answer names were not rewritten back, so the answer refers to the target itself
+	target := normalizeName(expectedResult)
+	for _, record := range result.Records {
+		if record.Name == target {
+			return true, nil
+		}
+	}
+	// target is resolved through a CNAME chain, which must then be part of the answer as well
+	if expectedCnames := cnames(expected); len(expectedCnames) > 0 {
+		resultCnames := cnames(result)
+		for _, cname := range expectedCnames {
+			if !slices.Contains(resultCnames, cname) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+	// target resolves directly to addresses; this is only accepted as evidence if both answers are authoritative
+	// (e.g. served by the kubernetes plugin), and contain the same addresses; non-authoritative answers (that is,
+	// forwarded ones) may coincidentally overlap without any rewrite being active
+	return result.Authoritative && expected.Authoritative && slices.Equal(result.Addresses(), expected.Addresses()), nil
+}
+
+// return CNAME targets contained in a lookup result
+func cnames(result *dnsutil.LookupResult) []string {
+	var names []string
+	for _, record := range result.Records {
+		if record.Type == "CNAME" {
+			names = append(names, record.Value)
+		}
+	}
+	return names
+}
+
+// lowercase given DNS name, and strip trailing dot (the form used by dnsutil.Record)
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
+
 // discover endpoints of the kube-system/kube-dns service in target cluster
 func discoverEndpoints(ctx context.Context, client client.Client) ([]Endpoint, error) {
 	// TODO: parameterize things
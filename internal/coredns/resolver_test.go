@@ -0,0 +1,177 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package coredns
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"github.com/sap/dns-masquerading-operator/internal/dnsutil"
+)
+
+// answer of the in-process nameserver for a specific name and type
+type answer struct {
+	authoritative bool
+	records       []string
+}
+
+// start an in-process nameserver (UDP and TCP on the same port of the loopback interface), serving the given answers,
+// which are keyed by '<qtype> <fqdn>' (e.g. 'A www.example.io.'); all other queries are answered with NXDOMAIN
+func startServer(t *testing.T, answers map[string]answer) (string, uint16) {
+	t.Helper()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error listening on udp: %s", err)
+	}
+	port := pc.LocalAddr().(*net.UDPAddr).Port
+	l, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	if err != nil {
+		pc.Close()
+		t.Fatalf("error listening on tcp: %s", err)
+	}
+
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		msg := new(dns.Msg)
+		msg.SetReply(req)
+		q := req.Question[0]
+		a, ok := answers[dns.TypeToString[q.Qtype]+" "+q.Name]
+		if !ok {
+			msg.Authoritative = true
+			msg.Rcode = dns.RcodeNameError
+			w.WriteMsg(msg)
+			return
+		}
+		msg.Authoritative = a.authoritative
+		for _, s := range a.records {
+			rr, err := dns.NewRR(s)
+			if err != nil {
+				t.Errorf("invalid record %s: %s", s, err)
+				continue
+			}
+			msg.Answer = append(msg.Answer, rr)
+		}
+		w.WriteMsg(msg)
+	})
+
+	for _, server := range []*dns.Server{{PacketConn: pc, Handler: handler}, {Listener: l, Handler: handler}} {
+		started := make(chan struct{})
+		server.NotifyStartedFunc = func() { close(started) }
+		go server.ActivateAndServe()
+		<-started
+		t.Cleanup(func() { server.Shutdown() })
+	}
+
+	return "127.0.0.1", uint16(port)
+}
+
+func TestCheckRewrite(t *testing.T) {
+	answers := map[string]answer{
+		// target service, served authoritatively by the kubernetes plugin
+		"A istio-ingressgateway.istio-system.svc.cluster.local.": {true, []string{"istio-ingressgateway.istio-system.svc.cluster.local. 5 IN A 10.0.0.1"}},
+		// rewrite without answer name rewriting
+		"A plain.example.io.": {true, []string{"istio-ingressgateway.istio-system.svc.cluster.local. 5 IN A 10.0.0.1"}},
+		// rewrite with answer name rewriting
+		"A rewritten.example.io.": {true, []string{"rewritten.example.io. 5 IN A 10.0.0.1"}},
+		// rewrite, but different addresses (e.g. stale answer)
+		"A stale.example.io.": {true, []string{"stale.example.io. 5 IN A 10.0.0.2"}},
+		// target outside the cluster, resolved through a CNAME chain
+		"A lb.example.com.": {false, []string{"lb.example.com. 60 IN CNAME lb.cdn.example.net.", "lb.cdn.example.net. 60 IN A 192.0.2.1"}},
+		"A cname.example.io.": {false, []string{
+			"cname.example.io. 60 IN CNAME lb.cdn.example.net.",
+			"lb.cdn.example.net. 60 IN A 192.0.2.1",
+		}},
+		// target outside the cluster, resolved directly to addresses
+		"A www.example.com.": {false, []string{"www.example.com. 60 IN A 192.0.2.10", "www.example.com. 60 IN A 192.0.2.11"}},
+		// no rewrite active, but the forwarded answer coincidentally overlaps with the target's addresses
+		"A overlap.example.io.": {false, []string{"overlap.example.io. 60 IN A 192.0.2.11"}},
+		// hosts plugin
+		"A hosts.example.io.":     {true, []string{"hosts.example.io. 10 IN A 1.2.3.4"}},
+		"A forwarded.example.io.": {false, []string{"forwarded.example.io. 60 IN A 1.2.3.4"}},
+		"AAAA hosts6.example.io.": {true, []string{"hosts6.example.io. 10 IN AAAA 2001:db8::1"}},
+		"A multiple.example.io.":  {true, []string{"multiple.example.io. 10 IN A 1.2.3.4", "multiple.example.io. 10 IN A 1.2.3.5"}},
+	}
+	address, port := startServer(t, answers)
+	client := dnsutil.NewClient(dnsutil.NetworkUDP, time.Second, 1)
+
+	tests := []struct {
+		name     string
+		host     string
+		expected string
+		active   bool
+	}{
+		{name: "answer refers to target", host: "plain.example.io", expected: "istio-ingressgateway.istio-system.svc.cluster.local", active: true},
+		{name: "answer refers to target (fqdn, mixed case)", host: "plain.example.io", expected: "Istio-Ingressgateway.istio-system.svc.cluster.local.", active: true},
+		{name: "authoritative answers with same addresses", host: "rewritten.example.io", expected: "istio-ingressgateway.istio-system.svc.cluster.local", active: true},
+		{name: "authoritative answers with different addresses", host: "stale.example.io", expected: "istio-ingressgateway.istio-system.svc.cluster.local", active: false},
+		{name: "cname chain of target", host: "cname.example.io", expected: "lb.example.com", active: true},
+		{name: "non-authoritative overlapping answers", host: "overlap.example.io", expected: "www.example.com", active: false},
+		{name: "host not found", host: "missing.example.io", expected: "www.example.com", active: false},
+		{name: "ip target, authoritative", host: "hosts.example.io", expected: "1.2.3.4", active: true},
+		{name: "ip target, non-authoritative", host: "forwarded.example.io", expected: "1.2.3.4", active: false},
+		{name: "ipv6 target, authoritative", host: "hosts6.example.io", expected: "2001:db8::1", active: true},
+		{name: "ip target, additional addresses", host: "multiple.example.io", expected: "1.2.3.4", active: false},
+		{name: "empty target, host not found", host: "missing.example.io", expected: "", active: true},
+		{name: "empty target, host found", host: "hosts.example.io", expected: "", active: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			active, err := checkRewrite(context.Background(), client, address, port, tt.host, tt.expected)
+			if err != nil {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+			if active != tt.active {
+				t.Errorf("got %t, want %t", active, tt.active)
+			}
+		})
+	}
+}
+
+func TestCheckRecordRemoved(t *testing.T) {
+	answers := map[string]answer{
+		"A kubernetes.default.svc.cluster.local.": {true, []string{"kubernetes.default.svc.cluster.local. 5 IN A 10.0.0.1"}},
+		// rewrite still active
+		"A active.example.io.":     {true, []string{"active.example.io. 10 IN A 1.2.3.4"}},
+		"A active-svc.example.io.": {true, []string{"kubernetes.default.svc.cluster.local. 5 IN A 10.0.0.1"}},
+		// rewrite removed, but the host resolves to the former target upstream
+		"A upstream.example.io.":     {false, []string{"upstream.example.io. 60 IN A 1.2.3.4"}},
+		"A upstream-svc.example.io.": {false, []string{"upstream-svc.example.io. 60 IN A 10.0.0.1"}},
+	}
+	address, port := startServer(t, answers)
+
+	tests := []struct {
+		name    string
+		host    string
+		former  string
+		removed bool
+	}{
+		{name: "ip target, still active", host: "active.example.io", former: "1.2.3.4", removed: false},
+		{name: "name target, still active", host: "active-svc.example.io", former: "kubernetes.default.svc.cluster.local", removed: false},
+		{name: "ip target, removed, host not found", host: "missing.example.io", former: "1.2.3.4", removed: true},
+		{name: "ip target, removed, resolving to target upstream", host: "upstream.example.io", former: "1.2.3.4", removed: true},
+		{name: "name target, removed, resolving to target addresses upstream", host: "upstream-svc.example.io", former: "kubernetes.default.svc.cluster.local", removed: true},
+	}
+	for _, mode := range []VerificationMode{VerificationModeAddresses, VerificationModeRewrite} {
+		resolver := NewResolver(nil, nil, true, ResolverOptions{VerificationMode: mode, QueryTimeout: time.Second, QueryAttempts: 1}, Endpoint{Address: address, Port: port})
+		for _, tt := range tests {
+			t.Run(string(mode)+"/"+tt.name, func(t *testing.T) {
+				removed, err := resolver.CheckRecordRemoved(context.Background(), tt.host, tt.former)
+				if err != nil {
+					t.Fatalf("got unexpected error: %s", err)
+				}
+				if removed != tt.removed {
+					t.Errorf("got %t, want %t", removed, tt.removed)
+				}
+			})
+		}
+		resolver.Close()
+	}
+}
@@ -11,7 +11,7 @@ import (
 	"regexp"
 	"strings"
 
-	"github.com/sap/dns-masquerading-operator/internal/dnsutil"
+	"github.com/sap/dns-masquerading-operator/internal/dnsname"
 	"github.com/sap/go-generics/maps"
 	"github.com/sap/go-generics/slices"
 )
@@ -23,21 +23,37 @@ type RewriteRule struct {
 	to    string
 }
 
-// Create new RewriteRule object (and validate input)
+var (
+	// Options used to validate the source (from) of a RewriteRule
+	SourceNameOptions = dnsname.Options{AllowWildcard: true, AllowUnderscore: true, AllowUnicode: true}
+	// Options used to validate the target (to) of a RewriteRule, if it is not an IP address
+	TargetNameOptions = dnsname.Options{AllowUnderscore: true, AllowUnicode: true}
+)
+
+// Create new RewriteRule object (and validate input);
+// internationalized names are stored (and rendered into the coredns configuration) in their ASCII (punycode) form
 func NewRewriteRule(owner string, from string, to string) (*RewriteRule, error) {
-	if err := dnsutil.CheckDnsName(from, false, true); err != nil {
+	fromName, err := dnsname.Parse(from, SourceNameOptions)
+	if err != nil {
 		return nil, err
 	}
 	if net.ParseIP(to) == nil {
-		if err := dnsutil.CheckDnsName(to, false, false); err != nil {
+		toName, err := dnsname.Parse(to, TargetNameOptions)
+		if err != nil {
 			return nil, err
 		}
+		to = toName.String()
 	} else {
-		if strings.Split(from, ".")[0] == "*" {
+		if fromName.IsWildcard() {
 			return nil, fmt.Errorf("error validating rewrite rule: source must not be a wildcard DNS name if target is an IP address")
 		}
 	}
-	return &RewriteRule{owner: owner, from: from, to: to}, nil
+	return &RewriteRule{owner: owner, from: fromName.String(), to: to}, nil
+}
+
+// Build owner identifier of the RewriteRule derived from a MasqueradingRule object
+func RewriteRuleOwner(uid string, namespace string, name string) string {
+	return fmt.Sprintf("%s (%s/%s)", uid, namespace, name)
 }
 
 // Return owner of a RewriteRule
@@ -77,6 +93,18 @@ func (r *RewriteRule) toIsIpaddress() bool {
 	return net.ParseIP(r.to) != nil
 }
 
+// Error returned by RewriteRuleSet.AddRule() if the rule to be added clashes with an existing rule of another owner
+type ConflictError struct {
+	// Rule which was to be added
+	Rule *RewriteRule
+	// Existing rule clashing with Rule
+	ConflictingRule *RewriteRule
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("error adding rewrite rule %s:%s (%s); conflicts with rule %s:%s (%s)", e.Rule.from, e.Rule.to, e.Rule.owner, e.ConflictingRule.from, e.ConflictingRule.to, e.ConflictingRule.owner)
+}
+
 // Set of RewriteRule
 type RewriteRuleSet struct {
 	rulesByOwner map[string]*RewriteRule
@@ -192,7 +220,7 @@ func (rs *RewriteRuleSet) AddRule(r *RewriteRule) (bool, error) {
 		}
 	}
 	if s != nil {
-		return false, fmt.Errorf("error adding rewrite rule %s:%s (%s); conflicts with rule %s:%s (%s)", r.from, r.to, r.owner, s.from, s.to, s.owner)
+		return false, &ConflictError{Rule: r, ConflictingRule: s}
 	}
 	s = rs.rulesByOwner[r.owner]
 	changed := s == nil || r.from != s.from || r.to != s.to
@@ -6,6 +6,7 @@ SPDX-License-Identifier: Apache-2.0
 package coredns
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -252,6 +253,19 @@ func TestAddRule10(t *testing.T) {
 	}
 }
 
+func TestAddRule11(t *testing.T) {
+	testName := "add rule with new owner and conflicting from (conflict error)"
+	rs := createSampleRuleSet()
+	_, err := rs.AddRule(mustNewRewriteRule(owner9, from1, to9))
+	var conflictErr *ConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("%s: got unexpected error: %v", testName, err)
+	}
+	if conflictErr.Rule.Owner() != owner9 || conflictErr.ConflictingRule.Owner() != owner1 {
+		t.Errorf("%s: unexpected conflict error: %s", testName, conflictErr)
+	}
+}
+
 func TestRemoveRule1(t *testing.T) {
 	testName := "remove existing rule"
 	rs := createSampleRuleSet()
@@ -299,3 +313,65 @@ func TestParseRuleSet(t *testing.T) {
 		t.Errorf("%s: unexpected ruleset", testName)
 	}
 }
+
+func TestNewRewriteRuleIDN(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     string
+		to       string
+		wantFrom string
+		wantTo   string
+		wantErr  bool
+	}{
+		{
+			name:     "unicode source",
+			from:     "bücher.example.io",
+			to:       to1,
+			wantFrom: "xn--bcher-kva.example.io",
+			wantTo:   to1,
+		},
+		{
+			name:     "unicode wildcard source and unicode target",
+			from:     "*.bücher.example.io",
+			to:       "bücher.example.io",
+			wantFrom: "*.xn--bcher-kva.example.io",
+			wantTo:   "xn--bcher-kva.example.io",
+		},
+		{
+			name:     "punycode source",
+			from:     "xn--bcher-kva.example.io",
+			to:       to4,
+			wantFrom: "xn--bcher-kva.example.io",
+			wantTo:   to4,
+		},
+		{
+			name:    "unicode label exceeding 63 characters when encoded",
+			from:    strings.Repeat("a", 60) + "ü.example.io",
+			to:      to1,
+			wantErr: true,
+		},
+		{
+			name:    "unicode name exceeding 253 characters when encoded",
+			from:    strings.Repeat(strings.Repeat("a", 45)+"ü.", 5) + "example.io",
+			to:      to1,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewRewriteRule(owner1, tt.from, tt.to)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got rule %s -> %s", r.From(), r.To())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+			if r.From() != tt.wantFrom || r.To() != tt.wantTo {
+				t.Errorf("got %s -> %s, want %s -> %s", r.From(), r.To(), tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
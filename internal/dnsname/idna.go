@@ -0,0 +1,62 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dnsname
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// IDNA2008 profile (with UTS #46 lookup mapping) used to convert internationalized DNS names;
+// note: the profile does not enforce LDH rules and length limits, since these are checked by Parse (on the encoded form)
+var idnaProfile = idna.New(
+	idna.MapForLookup(),
+	idna.StrictDomainName(false),
+	idna.Transitional(false),
+	idna.BidiRule(),
+)
+
+// Convert given DNS name into its ASCII (punycode) form; a leading wildcard label (*) is preserved;
+// the name is not validated otherwise (use Parse for that).
+func ToASCII(name string) (string, error) {
+	prefix, name := splitWildcard(name)
+	s, err := idnaProfile.ToASCII(name)
+	if err != nil {
+		return "", fmt.Errorf("invalid internationalized name: %s", err)
+	}
+	return prefix + s, nil
+}
+
+// Convert given DNS name into its Unicode form; a leading wildcard label (*) is preserved;
+// the name is not validated otherwise (use Parse for that).
+func ToUnicode(name string) (string, error) {
+	prefix, name := splitWildcard(name)
+	s, err := idnaProfile.ToUnicode(name)
+	if err != nil {
+		return "", fmt.Errorf("invalid internationalized name: %s", err)
+	}
+	return prefix + s, nil
+}
+
+// Check if given string contains non-ASCII characters.
+func IsUnicode(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return true
+		}
+	}
+	return false
+}
+
+// split off leading wildcard label (since it is not accepted by the idna profile)
+func splitWildcard(name string) (string, string) {
+	if rest, ok := strings.CutPrefix(name, "*."); ok {
+		return "*.", rest
+	}
+	return "", name
+}
@@ -0,0 +1,190 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package dnsname provides parsing and validation of DNS names (according to RFC1035/RFC1123, with optional support for
+// wildcards, service labels and internationalized names).
+package dnsname
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// Maximum length of a single label
+	MaxLabelLength = 63
+	// Maximum length of a name (in ASCII form, without trailing dot)
+	MaxNameLength = 253
+)
+
+// Options controlling which DNS names are accepted by Parse; the zero value accepts lowercase RFC1123 hostnames only.
+type Options struct {
+	// Accept uppercase letters
+	AllowUppercase bool
+	// Accept an asterisk (*) as first label
+	AllowWildcard bool
+	// Accept a trailing dot (fully qualified notation); the dot is not part of the parsed name
+	AllowTrailingDot bool
+	// Accept labels starting with an underscore, such as service labels (_sip._tcp)
+	AllowUnderscore bool
+	// Accept internationalized names (in Unicode notation); such names are validated on their ASCII (punycode) form
+	AllowUnicode bool
+}
+
+// LabelError describes an invalid label of a DNS name.
+type LabelError struct {
+	// Index of the invalid label (starting with 0)
+	Index int
+	// The invalid label (in ASCII form)
+	Label string
+	// Reason why the label is invalid
+	Reason string
+}
+
+func (e *LabelError) Error() string {
+	return fmt.Sprintf("label %d (%q): %s", e.Index+1, e.Label, e.Reason)
+}
+
+// Name represents a parsed DNS name.
+type Name struct {
+	labels []string
+}
+
+// Parse and validate given DNS name according to given options.
+func Parse(s string, opts Options) (*Name, error) {
+	name, err := parse(s, opts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid DNS name %s", s)
+	}
+	return name, nil
+}
+
+// Validate given DNS name according to given options.
+func Validate(s string, opts Options) error {
+	_, err := Parse(s, opts)
+	return err
+}
+
+func parse(s string, opts Options) (*Name, error) {
+	if s == "" {
+		return nil, fmt.Errorf("empty name")
+	}
+	if strings.HasSuffix(s, ".") && s != "." {
+		if !opts.AllowTrailingDot {
+			return nil, fmt.Errorf("trailing dot not allowed")
+		}
+		s = s[:len(s)-1]
+	}
+	if IsUnicode(s) {
+		if !opts.AllowUnicode {
+			return nil, fmt.Errorf("non-ASCII characters not allowed")
+		}
+		if !opts.AllowUppercase && strings.IndexFunc(s, unicode.IsUpper) >= 0 {
+			return nil, fmt.Errorf("uppercase characters not allowed")
+		}
+		var err error
+		if s, err = ToASCII(s); err != nil {
+			return nil, err
+		}
+	}
+
+	labels := strings.Split(s, ".")
+	for i, label := range labels {
+		if reason := checkLabel(label, i, opts); reason != "" {
+			return nil, &LabelError{Index: i, Label: label, Reason: reason}
+		}
+	}
+	if tld := labels[len(labels)-1]; isNumeric(tld) {
+		return nil, &LabelError{Index: len(labels) - 1, Label: tld, Reason: "top-level label must not be all-numeric"}
+	}
+	if len(s) > MaxNameLength {
+		return nil, fmt.Errorf("name exceeds %d characters", MaxNameLength)
+	}
+	return &Name{labels: labels}, nil
+}
+
+// check a single label (in ASCII form); returns the reason if the label is invalid, and an empty string otherwise
+func checkLabel(label string, index int, opts Options) string {
+	if label == "" {
+		return "empty label"
+	}
+	if len(label) > MaxLabelLength {
+		return fmt.Sprintf("label exceeds %d characters", MaxLabelLength)
+	}
+	if label == "*" {
+		if !opts.AllowWildcard {
+			return "wildcard not allowed"
+		}
+		if index > 0 {
+			return "wildcard only allowed as first label"
+		}
+		return ""
+	}
+	if opts.AllowUnderscore && label[0] == '_' {
+		label = label[1:]
+		if label == "" {
+			return "empty service label"
+		}
+	}
+	for i := 0; i < len(label); i++ {
+		c := label[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+		case c >= 'A' && c <= 'Z':
+			if !opts.AllowUppercase {
+				return "uppercase characters not allowed"
+			}
+		case c == '-':
+			if i == 0 || i == len(label)-1 {
+				return "label must not start or end with a hyphen"
+			}
+		default:
+			return fmt.Sprintf("invalid character %q", c)
+		}
+	}
+	if opts.AllowUnicode && strings.HasPrefix(strings.ToLower(label), "xn--") {
+		if _, err := idnaProfile.ToUnicode(label); err != nil {
+			return "invalid punycode label"
+		}
+	}
+	return ""
+}
+
+// check if given label consists of digits only
+func isNumeric(label string) bool {
+	for i := 0; i < len(label); i++ {
+		if label[i] < '0' || label[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Return the labels of the name (in ASCII form).
+func (n *Name) Labels() []string {
+	return append([]string{}, n.labels...)
+}
+
+// Check if the name is a wildcard name (that is, if its first label is an asterisk).
+func (n *Name) IsWildcard() bool {
+	return n.labels[0] == "*"
+}
+
+// Return the name in ASCII (punycode) form, without trailing dot.
+func (n *Name) String() string {
+	return strings.Join(n.labels, ".")
+}
+
+// Return the name in Unicode form, without trailing dot; if the name cannot be converted, the ASCII form is returned.
+func (n *Name) Unicode() string {
+	s, err := ToUnicode(n.String())
+	if err != nil {
+		return n.String()
+	}
+	return s
+}
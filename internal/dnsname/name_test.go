@@ -0,0 +1,216 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dnsname
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     Options
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "simple name",
+			input:    "www.example.io",
+			expected: "www.example.io",
+		},
+		{
+			name:     "single label",
+			input:    "localhost",
+			expected: "localhost",
+		},
+		{
+			name:    "empty name",
+			input:   "",
+			wantErr: true,
+		},
+		{
+			name:    "empty label",
+			input:   "www..example.io",
+			wantErr: true,
+		},
+		{
+			name:    "uppercase not allowed",
+			input:   "WWW.example.io",
+			wantErr: true,
+		},
+		{
+			name:     "uppercase allowed",
+			input:    "WWW.example.io",
+			opts:     Options{AllowUppercase: true},
+			expected: "WWW.example.io",
+		},
+		{
+			name:    "trailing dot not allowed",
+			input:   "www.example.io.",
+			wantErr: true,
+		},
+		{
+			name:     "trailing dot allowed",
+			input:    "www.example.io.",
+			opts:     Options{AllowTrailingDot: true},
+			expected: "www.example.io",
+		},
+		{
+			name:    "wildcard not allowed",
+			input:   "*.example.io",
+			wantErr: true,
+		},
+		{
+			name:     "wildcard allowed",
+			input:    "*.example.io",
+			opts:     Options{AllowWildcard: true},
+			expected: "*.example.io",
+		},
+		{
+			name:    "wildcard in inner label",
+			input:   "www.*.example.io",
+			opts:    Options{AllowWildcard: true},
+			wantErr: true,
+		},
+		{
+			name:    "service labels not allowed",
+			input:   "_sip._tcp.example.io",
+			wantErr: true,
+		},
+		{
+			name:     "service labels allowed",
+			input:    "_sip._tcp.example.io",
+			opts:     Options{AllowUnderscore: true},
+			expected: "_sip._tcp.example.io",
+		},
+		{
+			name:    "underscore inside label",
+			input:   "s_ip.example.io",
+			opts:    Options{AllowUnderscore: true},
+			wantErr: true,
+		},
+		{
+			name:    "leading hyphen",
+			input:   "-www.example.io",
+			wantErr: true,
+		},
+		{
+			name:    "trailing hyphen",
+			input:   "www-.example.io",
+			wantErr: true,
+		},
+		{
+			name:     "numeric inner label",
+			input:    "123.example.io",
+			expected: "123.example.io",
+		},
+		{
+			name:    "numeric top-level label",
+			input:   "www.example.123",
+			wantErr: true,
+		},
+		{
+			name:    "label too long",
+			input:   strings.Repeat("a", 64) + ".example.io",
+			wantErr: true,
+		},
+		{
+			name:     "label of maximum length",
+			input:    strings.Repeat("a", 63) + ".example.io",
+			expected: strings.Repeat("a", 63) + ".example.io",
+		},
+		{
+			name:    "name too long",
+			input:   strings.Repeat(strings.Repeat("a", 50)+".", 5) + "io",
+			wantErr: true,
+		},
+		{
+			name:    "unicode not allowed",
+			input:   "bücher.example.io",
+			wantErr: true,
+		},
+		{
+			name:     "unicode allowed",
+			input:    "bücher.example.io",
+			opts:     Options{AllowUnicode: true},
+			expected: "xn--bcher-kva.example.io",
+		},
+		{
+			name:     "unicode wildcard",
+			input:    "*.bücher.example.io",
+			opts:     Options{AllowUnicode: true, AllowWildcard: true},
+			expected: "*.xn--bcher-kva.example.io",
+		},
+		{
+			name:    "unicode uppercase not allowed",
+			input:   "Bücher.example.io",
+			opts:    Options{AllowUnicode: true},
+			wantErr: true,
+		},
+		{
+			name:    "unicode label too long when encoded",
+			input:   strings.Repeat("a", 60) + "ü.example.io",
+			opts:    Options{AllowUnicode: true},
+			wantErr: true,
+		},
+		{
+			name:     "punycode label",
+			input:    "xn--bcher-kva.example.io",
+			opts:     Options{AllowUnicode: true},
+			expected: "xn--bcher-kva.example.io",
+		},
+		{
+			name:    "invalid punycode label",
+			input:   "xn--a.example.io",
+			opts:    Options{AllowUnicode: true},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, err := Parse(tt.input, tt.opts)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %s", name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+			if got := name.String(); got != tt.expected {
+				t.Errorf("got %s, want %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseLabelError(t *testing.T) {
+	_, err := Parse("www.exa_mple.io", Options{})
+	var labelErr *LabelError
+	if !errors.As(err, &labelErr) {
+		t.Fatalf("expected label error, got: %v", err)
+	}
+	if labelErr.Index != 1 || labelErr.Label != "exa_mple" {
+		t.Errorf("unexpected label error: %s", labelErr)
+	}
+}
+
+func TestNameUnicode(t *testing.T) {
+	name, err := Parse("*.xn--bcher-kva.example.io", Options{AllowWildcard: true, AllowUnicode: true})
+	if err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	if !name.IsWildcard() {
+		t.Errorf("expected wildcard name")
+	}
+	if got, want := name.Unicode(), "*.bücher.example.io"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
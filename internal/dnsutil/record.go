@@ -9,32 +9,229 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/hashicorp/go-multierror"
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
 	"github.com/sap/go-generics/slices"
 )
 
-// Lookup a DNS name on the specified DNS server, and return all IP addresses;
-// returned slice of addresses will be nil if host was not found;
-// if host is an IP address, it will be returned as such;
-// err will be set for all other error situations.
-func Lookup(host string, serverAddress string, serverPort uint16) ([]string, error) {
-	r := &net.Resolver{
-		PreferGo: true,
-		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
-			d := net.Dialer{
-				Timeout: 5 * time.Second,
+const (
+	// Default timeout for a single query attempt
+	DefaultTimeout = 2 * time.Second
+	// Default number of attempts per query
+	DefaultAttempts = 3
+	// Default EDNS0 UDP buffer size (see https://www.dnsflagday.net/2020)
+	DefaultUDPSize = 1232
+)
+
+// Network to be used for DNS queries
+type Network string
+
+const (
+	// Use UDP, and retry over TCP if the response was truncated
+	NetworkUDP Network = "udp"
+	// Use TCP only (e.g. if the nameserver is reached through a port-forward)
+	NetworkTCP Network = "tcp"
+)
+
+// Client performs DNS queries against explicitly specified nameservers.
+type Client struct {
+	// Network to be used; defaults to NetworkUDP
+	Network Network
+	// Timeout for a single query attempt; defaults to DefaultTimeout
+	Timeout time.Duration
+	// Number of attempts per query; defaults to DefaultAttempts
+	Attempts int
+	// EDNS0 UDP buffer size advertised in queries; defaults to DefaultUDPSize
+	UDPSize uint16
+}
+
+// Create new DNS client; zero values for timeout and attempts mean that the according defaults will be used.
+func NewClient(network Network, timeout time.Duration, attempts int) *Client {
+	return &Client{
+		Network:  network,
+		Timeout:  timeout,
+		Attempts: attempts,
+	}
+}
+
+// Resource record as returned by a lookup; names are lowercase, and without trailing dot.
+type Record struct {
+	// Owner name of the record
+	Name string
+	// Record type (A, AAAA, CNAME or PTR)
+	Type string
+	// TTL of the record in seconds
+	TTL uint32
+	// Record data (IP address for A and AAAA, target name for CNAME and PTR)
+	Value string
+}
+
+// Result of a lookup.
+type LookupResult struct {
+	// Answer records (A, AAAA, CNAME), in the order returned by the nameserver
+	Records []Record
+	// Whether the nameserver answered authoritatively
+	Authoritative bool
+}
+
+// Return sorted and deduplicated IP addresses contained in the lookup result; nil if there are none.
+func (r *LookupResult) Addresses() []string {
+	var addresses []string
+	for _, record := range r.Records {
+		if (record.Type == "A" || record.Type == "AAAA") && !slices.Contains(addresses, record.Value) {
+			addresses = append(addresses, record.Value)
+		}
+	}
+	if addresses == nil {
+		return nil
+	}
+	return slices.Sort(addresses)
+}
+
+// Lookup A and AAAA records of a DNS name on the specified DNS server (CNAME records encountered
+// while resolving are returned as well); if host was not found, the returned result will have no records;
+// if host is an IP address, the result will contain one according record (with zero TTL);
+// err will be set for all other error situations (including cancellation of ctx).
+func (c *Client) Lookup(ctx context.Context, host string, serverAddress string, serverPort uint16) (*LookupResult, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		recordType := "A"
+		if ip.To4() == nil {
+			recordType = "AAAA"
+		}
+		return &LookupResult{Records: []Record{{Name: host, Type: recordType, Value: ip.String()}}}, nil
+	}
+
+	result := &LookupResult{}
+	for i, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		r, err := c.Query(ctx, host, qtype, serverAddress, serverPort)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range r.Records {
+			if !containsRecord(result.Records, record) {
+				result.Records = append(result.Records, record)
 			}
-			// force network to "tcp"; not sure if this is a good idea ...
-			return d.DialContext(ctx, "tcp", fmt.Sprintf("%s:%d", serverAddress, serverPort))
-		},
+		}
+		if i == 0 {
+			result.Authoritative = r.Authoritative
+		} else {
+			result.Authoritative = result.Authoritative && r.Authoritative
+		}
 	}
-	addresses, err := r.LookupHost(context.Background(), host)
+	return result, nil
+}
+
+// Query records of the given type (dns.TypeA, dns.TypeAAAA, dns.TypeCNAME, dns.TypePTR) for a DNS name on the specified DNS server;
+// answer records of other types are ignored; a non-existing name (NXDOMAIN) is not considered as an error.
+func (c *Client) Query(ctx context.Context, name string, qtype uint16, serverAddress string, serverPort uint16) (*LookupResult, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(name), qtype)
+	msg.SetEdns0(c.udpSize(), false)
+
+	response, err := c.exchange(ctx, msg, net.JoinHostPort(serverAddress, strconv.Itoa(int(serverPort))))
 	if err != nil {
-		if err, ok := err.(*net.DNSError); ok && err.IsNotFound {
-			return nil, nil
+		return nil, errors.Wrapf(err, "error querying %s records of %s on %s:%d", dns.TypeToString[qtype], name, serverAddress, serverPort)
+	}
+
+	result := &LookupResult{Authoritative: response.Authoritative}
+	switch response.Rcode {
+	case dns.RcodeSuccess:
+	case dns.RcodeNameError:
+		return result, nil
+	default:
+		return nil, fmt.Errorf("error querying %s records of %s on %s:%d: server returned %s", dns.TypeToString[qtype], name, serverAddress, serverPort, dns.RcodeToString[response.Rcode])
+	}
+	for _, rr := range response.Answer {
+		record := Record{
+			Name: normalizeName(rr.Header().Name),
+			Type: dns.TypeToString[rr.Header().Rrtype],
+			TTL:  rr.Header().Ttl,
+		}
+		switch rr := rr.(type) {
+		case *dns.A:
+			record.Value = rr.A.String()
+		case *dns.AAAA:
+			record.Value = rr.AAAA.String()
+		case *dns.CNAME:
+			record.Value = normalizeName(rr.Target)
+		case *dns.PTR:
+			record.Value = normalizeName(rr.Ptr)
+		default:
+			continue
+		}
+		result.Records = append(result.Records, record)
+	}
+	return result, nil
+}
+
+// send message to server, honoring the configured network, timeout and attempts;
+// in case of UDP, the query will be repeated over TCP if the response is truncated;
+// no further attempts are made once ctx is done.
+func (c *Client) exchange(ctx context.Context, msg *dns.Msg, address string) (*dns.Msg, error) {
+	network := c.Network
+	if network == "" {
+		network = NetworkUDP
+	}
+	attempts := c.Attempts
+	if attempts <= 0 {
+		attempts = DefaultAttempts
+	}
+
+	var merr error
+	for i := 0; i < attempts; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, multierror.Append(merr, err)
+		}
+		response, err := c.exchangeOnce(ctx, msg, address, network)
+		if err == nil && response.Truncated && network == NetworkUDP {
+			response, err = c.exchangeOnce(ctx, msg, address, NetworkTCP)
+		}
+		if err == nil {
+			return response, nil
 		}
-		return nil, err
+		merr = multierror.Append(merr, err)
 	}
-	return slices.Sort(addresses), nil
+	return nil, merr
+}
+
+// send message to server (single attempt); the attempt is bounded by the configured timeout, and by ctx
+func (c *Client) exchangeOnce(ctx context.Context, msg *dns.Msg, address string, network Network) (*dns.Msg, error) {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	client := &dns.Client{
+		Net:     string(network),
+		Timeout: timeout,
+		UDPSize: c.udpSize(),
+	}
+	response, _, err := client.ExchangeContext(ctx, msg, address)
+	return response, err
+}
+
+func (c *Client) udpSize() uint16 {
+	if c.UDPSize == 0 {
+		return DefaultUDPSize
+	}
+	return c.UDPSize
+}
+
+// check if records contain given record (ignoring TTL)
+func containsRecord(records []Record, record Record) bool {
+	for _, r := range records {
+		if r.Name == record.Name && r.Type == record.Type && r.Value == record.Value {
+			return true
+		}
+	}
+	return false
+}
+
+// lowercase given DNS name, and strip trailing dot
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
 }
@@ -0,0 +1,302 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dnsutil
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// start an in-process nameserver listening on UDP and TCP (same port) of the loopback interface;
+// the handler receives the network ("udp" or "tcp") the query was sent over
+func startServer(t *testing.T, handler func(network string, w dns.ResponseWriter, req *dns.Msg)) (string, uint16) {
+	t.Helper()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error listening on udp: %s", err)
+	}
+	port := pc.LocalAddr().(*net.UDPAddr).Port
+	l, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	if err != nil {
+		pc.Close()
+		t.Fatalf("error listening on tcp: %s", err)
+	}
+
+	for _, server := range []*dns.Server{{PacketConn: pc}, {Listener: l}} {
+		started := make(chan struct{})
+		server.NotifyStartedFunc = func() { close(started) }
+		server.Handler = dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+			handler(w.RemoteAddr().Network(), w, req)
+		})
+		go server.ActivateAndServe()
+		<-started
+		t.Cleanup(func() { server.Shutdown() })
+	}
+
+	return "127.0.0.1", uint16(port)
+}
+
+// create a reply to req, with the given answer records (in zone file format)
+func reply(t *testing.T, req *dns.Msg, authoritative bool, answer ...string) *dns.Msg {
+	t.Helper()
+	msg := new(dns.Msg)
+	msg.SetReply(req)
+	msg.Authoritative = authoritative
+	for _, s := range answer {
+		rr, err := dns.NewRR(s)
+		if err != nil {
+			t.Fatalf("invalid record %s: %s", s, err)
+		}
+		msg.Answer = append(msg.Answer, rr)
+	}
+	return msg
+}
+
+func TestLookup(t *testing.T) {
+	address, port := startServer(t, func(network string, w dns.ResponseWriter, req *dns.Msg) {
+		switch q := req.Question[0]; {
+		case q.Name == "www.example.io." && q.Qtype == dns.TypeA:
+			w.WriteMsg(reply(t, req, true, "www.example.io. 30 IN A 1.2.3.4", "www.example.io. 30 IN A 1.2.3.5"))
+		case q.Name == "www.example.io." && q.Qtype == dns.TypeAAAA:
+			w.WriteMsg(reply(t, req, true, "www.example.io. 30 IN AAAA 2001:db8::1"))
+		case q.Name == "alias.example.io." && q.Qtype == dns.TypeA:
+			w.WriteMsg(reply(t, req, false, "Alias.Example.io. 10 IN CNAME WWW.example.io.", "www.example.io. 30 IN A 1.2.3.4"))
+		case q.Name == "alias.example.io." && q.Qtype == dns.TypeAAAA:
+			w.WriteMsg(reply(t, req, true, "alias.example.io. 10 IN CNAME www.example.io.", "www.example.io. 30 IN AAAA 2001:db8::1"))
+		default:
+			msg := reply(t, req, true)
+			msg.Rcode = dns.RcodeNameError
+			w.WriteMsg(msg)
+		}
+	})
+	client := NewClient(NetworkUDP, time.Second, 1)
+
+	tests := []struct {
+		name          string
+		host          string
+		records       []Record
+		authoritative bool
+		addresses     []string
+	}{
+		{
+			name: "a and aaaa records",
+			host: "www.example.io",
+			records: []Record{
+				{Name: "www.example.io", Type: "A", TTL: 30, Value: "1.2.3.4"},
+				{Name: "www.example.io", Type: "A", TTL: 30, Value: "1.2.3.5"},
+				{Name: "www.example.io", Type: "AAAA", TTL: 30, Value: "2001:db8::1"},
+			},
+			authoritative: true,
+			addresses:     []string{"1.2.3.4", "1.2.3.5", "2001:db8::1"},
+		},
+		{
+			name: "cname chain, partially non-authoritative",
+			host: "alias.example.io",
+			records: []Record{
+				{Name: "alias.example.io", Type: "CNAME", TTL: 10, Value: "www.example.io"},
+				{Name: "www.example.io", Type: "A", TTL: 30, Value: "1.2.3.4"},
+				{Name: "www.example.io", Type: "AAAA", TTL: 30, Value: "2001:db8::1"},
+			},
+			authoritative: false,
+			addresses:     []string{"1.2.3.4", "2001:db8::1"},
+		},
+		{
+			name:          "nxdomain",
+			host:          "missing.example.io",
+			authoritative: true,
+		},
+		{
+			name:      "ip address",
+			host:      "2001:db8::2",
+			records:   []Record{{Name: "2001:db8::2", Type: "AAAA", Value: "2001:db8::2"}},
+			addresses: []string{"2001:db8::2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := client.Lookup(context.Background(), tt.host, address, port)
+			if err != nil {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(result.Records, tt.records) {
+				t.Errorf("got records %v, want %v", result.Records, tt.records)
+			}
+			if result.Authoritative != tt.authoritative {
+				t.Errorf("got authoritative %t, want %t", result.Authoritative, tt.authoritative)
+			}
+			if addresses := result.Addresses(); !reflect.DeepEqual(addresses, tt.addresses) {
+				t.Errorf("got addresses %v, want %v", addresses, tt.addresses)
+			}
+		})
+	}
+}
+
+func TestQueryPTR(t *testing.T) {
+	address, port := startServer(t, func(network string, w dns.ResponseWriter, req *dns.Msg) {
+		w.WriteMsg(reply(t, req, true, "4.3.2.1.in-addr.arpa. 30 IN PTR www.example.io."))
+	})
+
+	result, err := NewClient(NetworkUDP, time.Second, 1).Query(context.Background(), "4.3.2.1.in-addr.arpa", dns.TypePTR, address, port)
+	if err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	expected := []Record{{Name: "4.3.2.1.in-addr.arpa", Type: "PTR", TTL: 30, Value: "www.example.io"}}
+	if !reflect.DeepEqual(result.Records, expected) {
+		t.Errorf("got records %v, want %v", result.Records, expected)
+	}
+}
+
+func TestQueryServerFailure(t *testing.T) {
+	address, port := startServer(t, func(network string, w dns.ResponseWriter, req *dns.Msg) {
+		msg := reply(t, req, false)
+		msg.Rcode = dns.RcodeServerFailure
+		w.WriteMsg(msg)
+	})
+
+	if _, err := NewClient(NetworkUDP, time.Second, 1).Query(context.Background(), "www.example.io", dns.TypeA, address, port); err == nil {
+		t.Errorf("expected error, got none")
+	}
+}
+
+func TestQueryEDNS0(t *testing.T) {
+	var udpSize atomic.Uint32
+	address, port := startServer(t, func(network string, w dns.ResponseWriter, req *dns.Msg) {
+		if opt := req.IsEdns0(); opt != nil {
+			udpSize.Store(uint32(opt.UDPSize()))
+		}
+		w.WriteMsg(reply(t, req, true))
+	})
+
+	client := NewClient(NetworkUDP, time.Second, 1)
+	if _, err := client.Query(context.Background(), "www.example.io", dns.TypeA, address, port); err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	if size := udpSize.Load(); size != DefaultUDPSize {
+		t.Errorf("got advertised udp size %d, want %d", size, DefaultUDPSize)
+	}
+
+	client.UDPSize = 4096
+	if _, err := client.Query(context.Background(), "www.example.io", dns.TypeA, address, port); err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	if size := udpSize.Load(); size != 4096 {
+		t.Errorf("got advertised udp size %d, want %d", size, 4096)
+	}
+}
+
+func TestQueryTruncated(t *testing.T) {
+	var udpQueries, tcpQueries atomic.Int32
+	address, port := startServer(t, func(network string, w dns.ResponseWriter, req *dns.Msg) {
+		if network == "udp" {
+			udpQueries.Add(1)
+			msg := reply(t, req, true)
+			msg.Truncated = true
+			w.WriteMsg(msg)
+		} else {
+			tcpQueries.Add(1)
+			w.WriteMsg(reply(t, req, true, "www.example.io. 30 IN A 1.2.3.4"))
+		}
+	})
+
+	tests := []struct {
+		name       string
+		network    Network
+		udpQueries int32
+		tcpQueries int32
+	}{
+		{name: "udp with tcp fallback", network: NetworkUDP, udpQueries: 1, tcpQueries: 1},
+		{name: "tcp only", network: NetworkTCP, udpQueries: 0, tcpQueries: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			udpQueries.Store(0)
+			tcpQueries.Store(0)
+			result, err := NewClient(tt.network, time.Second, 1).Query(context.Background(), "www.example.io", dns.TypeA, address, port)
+			if err != nil {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+			if addresses := result.Addresses(); !reflect.DeepEqual(addresses, []string{"1.2.3.4"}) {
+				t.Errorf("got addresses %v, want %v", addresses, []string{"1.2.3.4"})
+			}
+			if n := udpQueries.Load(); n != tt.udpQueries {
+				t.Errorf("got %d udp queries, want %d", n, tt.udpQueries)
+			}
+			if n := tcpQueries.Load(); n != tt.tcpQueries {
+				t.Errorf("got %d tcp queries, want %d", n, tt.tcpQueries)
+			}
+		})
+	}
+}
+
+func TestQueryRetries(t *testing.T) {
+	var queries atomic.Int32
+	address, port := startServer(t, func(network string, w dns.ResponseWriter, req *dns.Msg) {
+		// drop the first query, so that the client runs into a timeout
+		if queries.Add(1) == 1 {
+			return
+		}
+		w.WriteMsg(reply(t, req, true, "www.example.io. 30 IN A 1.2.3.4"))
+	})
+
+	result, err := NewClient(NetworkUDP, 200*time.Millisecond, 2).Query(context.Background(), "www.example.io", dns.TypeA, address, port)
+	if err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	if addresses := result.Addresses(); !reflect.DeepEqual(addresses, []string{"1.2.3.4"}) {
+		t.Errorf("got addresses %v, want %v", addresses, []string{"1.2.3.4"})
+	}
+	if n := queries.Load(); n != 2 {
+		t.Errorf("got %d queries, want %d", n, 2)
+	}
+}
+
+func TestQueryTimeout(t *testing.T) {
+	var queries atomic.Int32
+	address, port := startServer(t, func(network string, w dns.ResponseWriter, req *dns.Msg) {
+		queries.Add(1)
+	})
+
+	start := time.Now()
+	_, err := NewClient(NetworkUDP, 100*time.Millisecond, 3).Query(context.Background(), "www.example.io", dns.TypeA, address, port)
+	if err == nil {
+		t.Fatalf("expected error, got none")
+	}
+	if n := queries.Load(); n != 3 {
+		t.Errorf("got %d queries, want %d", n, 3)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("query took %s, expected to be bounded by the configured timeout", elapsed)
+	}
+}
+
+func TestQueryCancelled(t *testing.T) {
+	var queries atomic.Int32
+	address, port := startServer(t, func(network string, w dns.ResponseWriter, req *dns.Msg) {
+		queries.Add(1)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := NewClient(NetworkUDP, 10*time.Second, 3).Query(ctx, "www.example.io", dns.TypeA, address, port)
+	if err == nil {
+		t.Fatalf("expected error, got none")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("query took %s, expected to be aborted when the context is done", elapsed)
+	}
+	if n := queries.Load(); n != 1 {
+		t.Errorf("got %d queries, want %d", n, 1)
+	}
+}
@@ -0,0 +1,142 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package portforward
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"k8s.io/client-go/rest"
+)
+
+// Target of a port-forward (pod and port).
+type Target struct {
+	Namespace string
+	Name      string
+	Port      uint16
+}
+
+// Pool of long-lived port-forwards; forwards are started on demand, kept alive across calls,
+// and restarted transparently if the underlying connection was lost.
+// A Pool is safe for concurrent use.
+type Pool struct {
+	config       *rest.Config
+	localAddress string
+	mu           sync.Mutex
+	forwards     map[Target]*PortForward
+	closed       bool
+	// starts a new port-forward; replaceable for testing
+	start func(ctx context.Context, target Target) (*PortForward, error)
+}
+
+// Create new Pool; forwards will listen on the given local address, using random local ports.
+func NewPool(cfg *rest.Config, localAddress string) *Pool {
+	p := &Pool{
+		config:       cfg,
+		localAddress: localAddress,
+		forwards:     make(map[Target]*PortForward),
+	}
+	p.start = p.startForward
+	return p
+}
+
+// Get running port-forward for the given target, starting (or restarting) it if necessary;
+// ctx bounds the startup of a new port-forward (but not its lifetime);
+// the returned handle is owned by the pool, and must not be stopped by the caller;
+// fails if the pool was closed.
+func (p *Pool) Get(ctx context.Context, target Target) (*PortForward, error) {
+	p.mu.Lock()
+	closed := p.closed
+	pfw, ok := p.forwards[target]
+	p.mu.Unlock()
+	if closed {
+		return nil, errPoolClosed(target)
+	}
+	if ok && pfw.Running() {
+		return pfw, nil
+	}
+
+	// start new port-forward without holding the lock, because this may take a while
+	newPfw, err := p.start(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		// pool was closed while starting; the new port-forward must not be added to it
+		newPfw.Stop()
+		return nil, errPoolClosed(target)
+	}
+	if pfw, ok := p.forwards[target]; ok {
+		if pfw.Running() {
+			// someone else was faster
+			newPfw.Stop()
+			return pfw, nil
+		}
+		pfw.Stop()
+	}
+	p.forwards[target] = newPfw
+	return newPfw, nil
+}
+
+// start new port-forward for the given target, listening on a random local port
+func (p *Pool) startForward(ctx context.Context, target Target) (*PortForward, error) {
+	p.mu.Lock()
+	closed := p.closed
+	p.mu.Unlock()
+	if closed {
+		return nil, errPoolClosed(target)
+	}
+	pfw := New(p.config, p.localAddress, 0, target.Namespace, target.Name, target.Port)
+	if err := pfw.Start(ctx); err != nil {
+		return nil, err
+	}
+	return pfw, nil
+}
+
+// Stop and remove the given port-forward (as returned by Get for the given target);
+// e.g. to enforce a reconnect after the forward turned out to be unusable;
+// has no effect if the pool meanwhile holds a different port-forward for the target (e.g. restarted by a concurrent caller).
+func (p *Pool) Invalidate(target Target, pfw *PortForward) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.forwards[target] == pfw {
+		pfw.Stop()
+		delete(p.forwards, target)
+	}
+}
+
+// Stop and remove all port-forwards whose target is not contained in the given list;
+// should be called whenever the set of relevant targets (e.g. service endpoints) changes.
+func (p *Pool) Retain(targets []Target) {
+	keep := make(map[Target]struct{}, len(targets))
+	for _, target := range targets {
+		keep[target] = struct{}{}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for target, pfw := range p.forwards {
+		if _, ok := keep[target]; !ok {
+			pfw.Stop()
+			delete(p.forwards, target)
+		}
+	}
+}
+
+// Stop and remove all port-forwards of the pool; subsequent calls to Get will fail.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+	p.Retain(nil)
+}
+
+func errPoolClosed(target Target) error {
+	return fmt.Errorf("error starting port forward to %s/%s:%d: pool closed", target.Namespace, target.Name, target.Port)
+}
@@ -0,0 +1,262 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package portforward
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// fake starter for port-forwards; the started handles run until they are stopped, or lost() is called
+type fakeStarter struct {
+	mu      sync.Mutex
+	started map[Target][]*PortForward
+	lost    map[*PortForward]chan struct{}
+	err     error
+}
+
+func newFakeStarter() *fakeStarter {
+	return &fakeStarter{
+		started: make(map[Target][]*PortForward),
+		lost:    make(map[*PortForward]chan struct{}),
+	}
+}
+
+func (s *fakeStarter) start(ctx context.Context, target Target) (*PortForward, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	pfw := New(nil, "127.0.0.1", 0, target.Namespace, target.Name, target.Port)
+	pfw.started = true
+	lostCh := make(chan struct{})
+	go func() {
+		defer close(pfw.doneCh)
+		select {
+		case <-pfw.stopCh:
+		case <-lostCh:
+		}
+	}()
+	s.started[target] = append(s.started[target], pfw)
+	s.lost[pfw] = lostCh
+	return pfw, nil
+}
+
+// simulate loss of the underlying connection
+func (s *fakeStarter) loseConnection(pfw *PortForward) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	close(s.lost[pfw])
+	<-pfw.doneCh
+}
+
+func (s *fakeStarter) count(target Target) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.started[target])
+}
+
+func newTestPool() (*Pool, *fakeStarter) {
+	starter := newFakeStarter()
+	pool := NewPool(nil, "127.0.0.1")
+	pool.start = starter.start
+	return pool, starter
+}
+
+var (
+	target1 = Target{Namespace: "kube-system", Name: "coredns-1", Port: 53}
+	target2 = Target{Namespace: "kube-system", Name: "coredns-2", Port: 53}
+)
+
+func TestPoolGet(t *testing.T) {
+	pool, starter := newTestPool()
+	defer pool.Close()
+
+	pfw1, err := pool.Get(context.Background(), target1)
+	if err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	pfw2, err := pool.Get(context.Background(), target1)
+	if err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	if pfw1 != pfw2 {
+		t.Errorf("expected running port-forward to be reused")
+	}
+	if n := starter.count(target1); n != 1 {
+		t.Errorf("got %d started port-forwards, want %d", n, 1)
+	}
+
+	starter.loseConnection(pfw1)
+	pfw3, err := pool.Get(context.Background(), target1)
+	if err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	if pfw3 == pfw1 || !pfw3.Running() {
+		t.Errorf("expected port-forward to be restarted after connection loss")
+	}
+	if n := starter.count(target1); n != 2 {
+		t.Errorf("got %d started port-forwards, want %d", n, 2)
+	}
+}
+
+func TestPoolGetError(t *testing.T) {
+	pool, starter := newTestPool()
+	defer pool.Close()
+
+	starter.err = fmt.Errorf("connection refused")
+	if _, err := pool.Get(context.Background(), target1); err == nil {
+		t.Fatalf("expected error, got none")
+	}
+
+	starter.err = nil
+	pfw, err := pool.Get(context.Background(), target1)
+	if err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	if !pfw.Running() {
+		t.Errorf("expected port-forward to be running")
+	}
+}
+
+func TestPoolGetConcurrent(t *testing.T) {
+	pool, starter := newTestPool()
+	defer pool.Close()
+
+	const n = 10
+	results := make([]*PortForward, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			pfw, err := pool.Get(context.Background(), target1)
+			if err != nil {
+				t.Errorf("got unexpected error: %s", err)
+				return
+			}
+			results[i] = pfw
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 1; i < n; i++ {
+		if results[i] != results[0] {
+			t.Fatalf("expected all callers to get the same port-forward")
+		}
+	}
+	starter.mu.Lock()
+	defer starter.mu.Unlock()
+	for _, pfw := range starter.started[target1] {
+		if pfw != results[0] && pfw.Running() {
+			t.Errorf("expected superfluous port-forward to be stopped")
+		}
+	}
+}
+
+func TestPoolInvalidate(t *testing.T) {
+	pool, starter := newTestPool()
+	defer pool.Close()
+
+	pfw1, err := pool.Get(context.Background(), target1)
+	if err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	pool.Invalidate(target1, pfw1)
+	if pfw1.Running() {
+		t.Errorf("expected invalidated port-forward to be stopped")
+	}
+	// invalidating an unknown target has no effect
+	pool.Invalidate(target2, pfw1)
+
+	pfw2, err := pool.Get(context.Background(), target1)
+	if err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	if pfw2 == pfw1 || !pfw2.Running() {
+		t.Errorf("expected new port-forward to be started after invalidation")
+	}
+	if n := starter.count(target1); n != 2 {
+		t.Errorf("got %d started port-forwards, want %d", n, 2)
+	}
+
+	// invalidating a stale port-forward must not affect its replacement
+	pool.Invalidate(target1, pfw1)
+	if !pfw2.Running() {
+		t.Errorf("expected replacing port-forward to be still running after invalidation of stale port-forward")
+	}
+	pfw3, err := pool.Get(context.Background(), target1)
+	if err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	if pfw3 != pfw2 {
+		t.Errorf("expected replacing port-forward to be reused after invalidation of stale port-forward")
+	}
+}
+
+func TestPoolRetainAndClose(t *testing.T) {
+	pool, _ := newTestPool()
+
+	pfw1, err := pool.Get(context.Background(), target1)
+	if err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	pfw2, err := pool.Get(context.Background(), target2)
+	if err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+
+	pool.Retain([]Target{target2})
+	if pfw1.Running() {
+		t.Errorf("expected port-forward of dropped target to be stopped")
+	}
+	if !pfw2.Running() {
+		t.Errorf("expected port-forward of retained target to be running")
+	}
+
+	pool.Close()
+	if pfw2.Running() {
+		t.Errorf("expected all port-forwards to be stopped after close")
+	}
+}
+
+func TestPoolClosed(t *testing.T) {
+	pool, starter := newTestPool()
+	pool.Close()
+
+	if _, err := pool.Get(context.Background(), target1); err == nil {
+		t.Errorf("expected error from closed pool, got none")
+	}
+	if n := starter.count(target1); n != 0 {
+		t.Errorf("got %d started port-forwards, want %d", n, 0)
+	}
+}
+
+func TestPoolClosedWhileStarting(t *testing.T) {
+	pool, starter := newTestPool()
+	var pfw *PortForward
+	pool.start = func(ctx context.Context, target Target) (*PortForward, error) {
+		var err error
+		pfw, err = starter.start(ctx, target)
+		pool.Close()
+		return pfw, err
+	}
+
+	if _, err := pool.Get(context.Background(), target1); err == nil {
+		t.Errorf("expected error from pool closed while starting, got none")
+	}
+	if pfw == nil || pfw.Running() {
+		t.Errorf("expected port-forward started while closing to be stopped")
+	}
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	if n := len(pool.forwards); n != 0 {
+		t.Errorf("got %d pooled port-forwards after close, want %d", n, 0)
+	}
+}
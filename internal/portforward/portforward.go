@@ -6,6 +6,7 @@ SPDX-License-Identifier: Apache-2.0
 package portforward
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -20,6 +21,8 @@ import (
 	"k8s.io/client-go/transport/spdy"
 )
 
+const defaultStartTimeout = 10 * time.Second
+
 // PortForward is a handle represents a port-forward connection.
 type PortForward struct {
 	config       *rest.Config
@@ -29,6 +32,7 @@ type PortForward struct {
 	name         string
 	port         uint16
 	stopCh       chan struct{}
+	doneCh       chan struct{}
 	mu           sync.Mutex
 	started      bool
 	stopped      bool
@@ -44,39 +48,64 @@ func New(cfg *rest.Config, localAddress string, localPort uint16, namespace stri
 		name:         name,
 		port:         port,
 		stopCh:       make(chan struct{}),
+		doneCh:       make(chan struct{}),
 	}
 }
 
-// Start port-forwarding; blocks up to 10 seconds, until port-forward is ready, or an error or timeout occurred;
+// Start port-forwarding; blocks until port-forward is ready, or an error occurred, or ctx is done;
+// if ctx has no deadline, a default timeout of 10 seconds applies; note that ctx only bounds the startup,
+// the lifetime of the port-forward is controlled through Stop().
 // Start() may be called only once (even after error); any further call will return an error.
-func (pfw *PortForward) Start() error {
+func (pfw *PortForward) Start(ctx context.Context) error {
 	pfw.mu.Lock()
 	defer pfw.mu.Unlock()
 	if pfw.started {
 		return fmt.Errorf("error starting port forward %s:%d to %s/%s:%d: already started", pfw.localAddress, pfw.localPort, pfw.namespace, pfw.name, pfw.port)
 	}
+	pfw.started = true
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultStartTimeout)
+		defer cancel()
+	}
+
 	path := fmt.Sprintf("/api/v1/namespaces/%s/pods/%s/portforward", pfw.namespace, pfw.name)
 	host := strings.TrimPrefix(pfw.config.Host, "https://")
 
 	transport, upgrader, err := spdy.RoundTripperFor(pfw.config)
 	if err != nil {
+		pfw.stopped = true
 		return errors.Wrapf(err, "error starting port forward %s:%d to %s/%s:%d", pfw.localAddress, pfw.localPort, pfw.namespace, pfw.name, pfw.port)
 	}
 
 	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, &url.URL{Scheme: "https", Path: path, Host: host})
 
 	readyCh := make(chan struct{})
-	errorCh := make(chan error)
+	errorCh := make(chan error, 1)
 	fw, err := portforward.NewOnAddresses(dialer, []string{pfw.localAddress}, []string{fmt.Sprintf("%d:%d", pfw.localPort, pfw.port)}, pfw.stopCh, readyCh, io.Discard, io.Discard)
 	if err != nil {
+		pfw.stopped = true
 		return errors.Wrapf(err, "error starting port forward %s:%d to %s/%s:%d", pfw.localAddress, pfw.localPort, pfw.namespace, pfw.name, pfw.port)
 	}
 	go func() {
+		defer close(pfw.doneCh)
 		if err := fw.ForwardPorts(); err != nil {
 			errorCh <- err
 		}
 	}()
 
+	if err := pfw.awaitReady(ctx, fw, readyCh, errorCh); err != nil {
+		// signal forwarding goroutine to terminate (without waiting, since it might still be dialing)
+		pfw.stopped = true
+		close(pfw.stopCh)
+		return err
+	}
+	return nil
+}
+
+// wait until port-forward is ready, and validate the forwarded ports
+func (pfw *PortForward) awaitReady(ctx context.Context, fw *portforward.PortForwarder, readyCh chan struct{}, errorCh chan error) error {
 	select {
 	case <-readyCh:
 		ports, err := fw.GetPorts()
@@ -93,18 +122,16 @@ func (pfw *PortForward) Start() error {
 			return fmt.Errorf("error starting port forward %s:%d to %s/%s:%d: invalid remote port returned (%d)", pfw.localAddress, pfw.localPort, pfw.namespace, pfw.name, pfw.port, ports[0].Remote)
 		}
 		pfw.localPort = ports[0].Local
-		pfw.started = true
 		return nil
 	case err := <-errorCh:
-		close(pfw.stopCh)
 		return errors.Wrapf(err, "error starting port forward %s:%d to %s/%s:%d", pfw.localAddress, pfw.localPort, pfw.namespace, pfw.name, pfw.port)
-	case <-time.After(10 * time.Second):
-		close(pfw.stopCh)
-		return fmt.Errorf("error starting port forward %s:%d to %s/%s:%d: timeout", pfw.localAddress, pfw.localPort, pfw.namespace, pfw.name, pfw.port)
+	case <-ctx.Done():
+		return errors.Wrapf(ctx.Err(), "error starting port forward %s:%d to %s/%s:%d", pfw.localAddress, pfw.localPort, pfw.namespace, pfw.name, pfw.port)
 	}
 }
 
-// Stop port-forwarding; calling Stop() on a not yet started or already stopped handle has no effect.
+// Stop port-forwarding, and wait until the forwarding goroutine has terminated;
+// calling Stop() on a not yet started or already stopped handle has no effect.
 func (pfw *PortForward) Stop() {
 	pfw.mu.Lock()
 	defer pfw.mu.Unlock()
@@ -113,6 +140,23 @@ func (pfw *PortForward) Stop() {
 	}
 	pfw.stopped = true
 	close(pfw.stopCh)
+	<-pfw.doneCh
+}
+
+// Check if port-forward is running; that is, if it was successfully started, was not stopped,
+// and the underlying connection was not lost in the meantime.
+func (pfw *PortForward) Running() bool {
+	pfw.mu.Lock()
+	defer pfw.mu.Unlock()
+	if !pfw.started || pfw.stopped {
+		return false
+	}
+	select {
+	case <-pfw.doneCh:
+		return false
+	default:
+		return true
+	}
 }
 
 func (pfw *PortForward) LocalAddress() string {
@@ -0,0 +1,126 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package portforward
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/rest"
+)
+
+// start a fake API server, answering port-forward requests with the given handler
+func startAPIServer(t *testing.T, handler http.HandlerFunc) *rest.Config {
+	t.Helper()
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+	return &rest.Config{Host: server.URL, TLSClientConfig: rest.TLSClientConfig{Insecure: true}}
+}
+
+// run f, and fail if it does not return within the given duration
+func within(t *testing.T, d time.Duration, f func()) {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f()
+	}()
+	select {
+	case <-done:
+	case <-time.After(d):
+		t.Fatalf("did not return within %s", d)
+	}
+}
+
+func TestStartCancelled(t *testing.T) {
+	release := make(chan struct{})
+	cfg := startAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		// never complete the upgrade, so that the port-forward hangs in startup
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	defer close(release)
+
+	pfw := New(cfg, "127.0.0.1", 0, "kube-system", "coredns", 53)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	var err error
+	within(t, 5*time.Second, func() { err = pfw.Start(ctx) })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+	if pfw.Running() {
+		t.Errorf("expected port-forward not to be running")
+	}
+
+	// Stop() must not wait for the forwarding goroutine, which might still be dialing
+	within(t, time.Second, pfw.Stop)
+
+	if err := pfw.Start(context.Background()); err == nil {
+		t.Errorf("expected error when starting twice, got none")
+	}
+}
+
+func TestStartDeadlineExceeded(t *testing.T) {
+	release := make(chan struct{})
+	cfg := startAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	defer close(release)
+
+	pfw := New(cfg, "127.0.0.1", 0, "kube-system", "coredns", 53)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	var err error
+	within(t, 5*time.Second, func() { err = pfw.Start(ctx) })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// ctx is done now; stopping must still return immediately
+	<-ctx.Done()
+	within(t, time.Second, pfw.Stop)
+	if pfw.Running() {
+		t.Errorf("expected port-forward not to be running")
+	}
+}
+
+func TestStartRejected(t *testing.T) {
+	cfg := startAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	})
+
+	pfw := New(cfg, "127.0.0.1", 0, "kube-system", "coredns", 53)
+
+	var err error
+	within(t, 5*time.Second, func() { err = pfw.Start(context.Background()) })
+	if err == nil {
+		t.Fatalf("expected error, got none")
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected error to be reported before the startup timeout, got %v", err)
+	}
+	within(t, time.Second, pfw.Stop)
+}
+
+func TestStopNotStarted(t *testing.T) {
+	pfw := New(&rest.Config{}, "127.0.0.1", 0, "kube-system", "coredns", 53)
+	within(t, time.Second, pfw.Stop)
+	if pfw.Running() {
+		t.Errorf("expected port-forward not to be running")
+	}
+}
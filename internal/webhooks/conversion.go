@@ -0,0 +1,115 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// path of the conversion webhook, as registered by controller-runtime
+const conversionWebhookPath = "/convert"
+
+// ConversionWebhookRegistrar points the conversion webhook of custom resource definitions to the webhook server of this operator;
+// that is, it sets the service reference and the ca bundle in the conversion section of the definitions, at startup, and then
+// periodically (in order to pick up rotated certificates, and to repair definitions which were re-applied with their shipped defaults)
+type ConversionWebhookRegistrar struct {
+	// Client used to patch the custom resource definitions
+	Client client.Client
+	// Names of the custom resource definitions
+	CustomResourceDefinitions []string
+	// Namespace of the service exposing the webhook server
+	ServiceNamespace string
+	// Name of the service exposing the webhook server
+	ServiceName string
+	// Port of the service exposing the webhook server
+	ServicePort int32
+	// Directory containing the serving certificate of the webhook server; the ca bundle is read from ca.crt,
+	// or, if that does not exist, from tls.crt (which then has to be self-signed)
+	CertDir string
+	// Interval between two registrations (the first registration runs immediately)
+	Interval time.Duration
+}
+
+// Start the registrar; implements manager.Runnable
+func (r *ConversionWebhookRegistrar) Start(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx).WithName("conversion-webhook-registrar")
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		if err := r.Register(ctx); err != nil {
+			log.Error(err, "error registering conversion webhook")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Run the registrar only on the leader; implements manager.LeaderElectionRunnable
+func (r *ConversionWebhookRegistrar) NeedLeaderElection() bool {
+	return true
+}
+
+// Set service reference and ca bundle of the conversion webhook of the managed custom resource definitions
+func (r *ConversionWebhookRegistrar) Register(ctx context.Context) error {
+	caBundle, err := os.ReadFile(filepath.Join(r.CertDir, "ca.crt"))
+	if os.IsNotExist(err) {
+		caBundle, err = os.ReadFile(filepath.Join(r.CertDir, "tls.crt"))
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to read ca bundle")
+	}
+
+	patch, err := json.Marshal(map[string]any{
+		"spec": map[string]any{
+			"conversion": map[string]any{
+				"strategy": "Webhook",
+				"webhook": map[string]any{
+					"clientConfig": map[string]any{
+						"service": map[string]any{
+							"namespace": r.ServiceNamespace,
+							"name":      r.ServiceName,
+							"path":      conversionWebhookPath,
+							"port":      r.ServicePort,
+						},
+						"caBundle": caBundle,
+						"url":      nil,
+					},
+					"conversionReviewVersions": []string{"v1"},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, name := range r.CustomResourceDefinitions {
+		crd := &unstructured.Unstructured{}
+		crd.SetGroupVersionKind(schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"})
+		crd.SetName(name)
+		if err := r.Client.Patch(ctx, crd, client.RawPatch(types.MergePatchType, patch)); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to patch custom resource definition %s", name))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
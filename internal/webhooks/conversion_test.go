@@ -0,0 +1,87 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhooks
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestConversionWebhookRegistrarRegister(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}
+	restMapper := meta.NewDefaultRESTMapper(nil)
+	restMapper.Add(gvk, meta.RESTScopeRoot)
+
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(gvk)
+	crd.SetName("masqueradingrules.dns.cs.sap.com")
+	if err := unstructured.SetNestedMap(crd.Object, map[string]any{
+		"strategy": "Webhook",
+		"webhook": map[string]any{
+			"clientConfig": map[string]any{
+				"service": map[string]any{"namespace": "default", "name": "dns-masquerading-operator-webhook", "path": "/convert", "port": int64(443)},
+			},
+			"conversionReviewVersions": []any{"v1"},
+		},
+	}, "spec", "conversion"); err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithRESTMapper(restMapper).WithObjects(crd).Build()
+
+	certDir := t.TempDir()
+	r := &ConversionWebhookRegistrar{
+		Client:                    c,
+		CustomResourceDefinitions: []string{"masqueradingrules.dns.cs.sap.com"},
+		ServiceNamespace:          "dns-masquerading-operator",
+		ServiceName:               "webhook",
+		ServicePort:               8443,
+		CertDir:                   certDir,
+	}
+
+	if err := r.Register(context.TODO()); err == nil {
+		t.Errorf("expected error for missing certificate, got none")
+	}
+
+	for _, file := range []string{"tls.crt", "ca.crt"} {
+		content := "certificate from " + file
+		if err := os.WriteFile(filepath.Join(certDir, file), []byte(content), 0644); err != nil {
+			t.Fatalf("error writing certificate: %s", err)
+		}
+		if err := r.Register(context.TODO()); err != nil {
+			t.Fatalf("got unexpected error: %s", err)
+		}
+
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: "masqueradingrules.dns.cs.sap.com"}, obj); err != nil {
+			t.Fatalf("got unexpected error: %s", err)
+		}
+		clientConfig, _, _ := unstructured.NestedMap(obj.Object, "spec", "conversion", "webhook", "clientConfig")
+		expected := map[string]any{
+			"service":  map[string]any{"namespace": "dns-masquerading-operator", "name": "webhook", "path": "/convert", "port": int64(8443)},
+			"caBundle": base64.StdEncoding.EncodeToString([]byte(content)),
+		}
+		if !reflect.DeepEqual(clientConfig, expected) {
+			t.Errorf("got client config %v, want %v", clientConfig, expected)
+		}
+	}
+
+	r.CustomResourceDefinitions = append(r.CustomResourceDefinitions, "missing.dns.cs.sap.com")
+	if err := r.Register(context.TODO()); err == nil {
+		t.Errorf("expected error for missing custom resource definition, got none")
+	}
+}
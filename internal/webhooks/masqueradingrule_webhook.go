@@ -7,21 +7,68 @@ package webhooks
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net"
+	"sort"
+	"strings"
 
 	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
 	"github.com/sap/dns-masquerading-operator/api/v1alpha1"
 	"github.com/sap/dns-masquerading-operator/internal/coredns"
+	"github.com/sap/dns-masquerading-operator/internal/dnsname"
 
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
+const (
+	masqueradingRuleFromIndex       = "spec.from"
+	masqueradingRuleFromParentIndex = "spec.from.parent"
+)
+
+// label carrying the uid of the resource a masquerading rule was generated for (set by the source controllers)
+const labelControllerUid = "dns.cs.sap.com/controller-uid"
+
+// How to handle masquerading rules clashing with existing rules
+type ConflictPolicy string
+
+const (
+	// Reject clashing rules
+	ConflictPolicyReject ConflictPolicy = "reject"
+	// Admit clashing rules, but return a warning
+	ConflictPolicyWarn ConflictPolicy = "warn"
+)
+
 type MasqueradingRuleWebhook struct {
 	Log logr.Logger
+	// Reader used to look up existing masquerading rules; if set, it must support the field indexes registered by SetupWebhookWithManager()
+	// (such as the manager's cache); if nil, no conflict detection happens (and short service names of the form 'svc.ns' are not expanded)
+	Client         client.Reader
+	ConflictPolicy ConflictPolicy
+	// Cluster domain used to expand short service names; if empty, short service names are not expanded
+	ClusterDomain string
 }
 
 func (w *MasqueradingRuleWebhook) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	if w.Client != nil {
+		if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &v1alpha1.MasqueradingRule{}, masqueradingRuleFromIndex, func(obj client.Object) []string {
+			return []string{asciiDnsName(obj.(*v1alpha1.MasqueradingRule).Spec.From)}
+		}); err != nil {
+			return errors.Wrapf(err, "failed to register field index %s", masqueradingRuleFromIndex)
+		}
+		if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &v1alpha1.MasqueradingRule{}, masqueradingRuleFromParentIndex, func(obj client.Object) []string {
+			return getParentDomains(asciiDnsName(obj.(*v1alpha1.MasqueradingRule).Spec.From))
+		}); err != nil {
+			return errors.Wrapf(err, "failed to register field index %s", masqueradingRuleFromParentIndex)
+		}
+	}
 	return ctrl.NewWebhookManagedBy(mgr, &v1alpha1.MasqueradingRule{}).
 		WithValidator(w).
 		WithDefaulter(w).
@@ -31,13 +78,22 @@ func (w *MasqueradingRuleWebhook) SetupWebhookWithManager(mgr ctrl.Manager) erro
 func (w *MasqueradingRuleWebhook) ValidateCreate(ctx context.Context, masqueradingRule *v1alpha1.MasqueradingRule) (admission.Warnings, error) {
 	w.Log.Info("validate create", "name", masqueradingRule.Name)
 
-	return nil, w.validate(masqueradingRule)
+	if err := w.validate(masqueradingRule); err != nil {
+		return nil, err
+	}
+	return w.checkConflicts(ctx, masqueradingRule)
 }
 
 func (w *MasqueradingRuleWebhook) ValidateUpdate(ctx context.Context, oldmasqueradingRule *v1alpha1.MasqueradingRule, masqueradingRule *v1alpha1.MasqueradingRule) (admission.Warnings, error) {
 	w.Log.Info("validate update", "name", masqueradingRule.Name)
 
-	return nil, w.validate(masqueradingRule)
+	if err := w.validate(masqueradingRule); err != nil {
+		return nil, err
+	}
+	if masqueradingRule.Spec.From == oldmasqueradingRule.Spec.From || !masqueradingRule.DeletionTimestamp.IsZero() {
+		return nil, nil
+	}
+	return w.checkConflicts(ctx, masqueradingRule)
 }
 
 func (w *MasqueradingRuleWebhook) ValidateDelete(ctx context.Context, masqueradingRule *v1alpha1.MasqueradingRule) (admission.Warnings, error) {
@@ -49,9 +105,71 @@ func (w *MasqueradingRuleWebhook) ValidateDelete(ctx context.Context, masqueradi
 func (w *MasqueradingRuleWebhook) Default(ctx context.Context, masqueradingRule *v1alpha1.MasqueradingRule) error {
 	w.Log.Info("default", "name", masqueradingRule.Name)
 
+	namespace := masqueradingRule.Namespace
+	if namespace == "" {
+		// note: the namespace may be missing in the object (e.g. in case of generateName usage), but is always part of the request
+		if req, err := admission.RequestFromContext(ctx); err == nil {
+			namespace = req.Namespace
+		}
+	}
+
+	// note: names of the form 'svc.ns' are only expanded if the target is new or changed (and not on any other update of the rule),
+	// since the result depends on the existence of services, which might have been created after the rule
+	expandServiceNames := true
+	if req, err := admission.RequestFromContext(ctx); err == nil && req.Operation == admissionv1.Update {
+		oldMasqueradingRule := &v1alpha1.MasqueradingRule{}
+		if err := json.Unmarshal(req.OldObject.Raw, oldMasqueradingRule); err == nil && normalizeDnsName(oldMasqueradingRule.Spec.To) == normalizeDnsName(masqueradingRule.Spec.To) {
+			expandServiceNames = false
+		}
+	}
+
+	masqueradingRule.Spec.From = normalizeDnsName(masqueradingRule.Spec.From)
+	to, err := w.defaultTarget(ctx, namespace, masqueradingRule.Spec.To, expandServiceNames)
+	if err != nil {
+		return err
+	}
+	masqueradingRule.Spec.To = to
+
 	return nil
 }
 
+// normalize given rewrite target; IP addresses are brought into canonical form; DNS names are normalized, and short service names
+// are expanded to fully qualified names (names with one label are considered as services in given namespace; names with two labels
+// are considered as 'service.namespace', if expandServiceNames is true, and such a service exists)
+func (w *MasqueradingRuleWebhook) defaultTarget(ctx context.Context, namespace string, to string, expandServiceNames bool) (string, error) {
+	to = strings.TrimSpace(to)
+	if ip := net.ParseIP(to); ip != nil {
+		return ip.String(), nil
+	}
+	to = normalizeDnsName(to)
+	if to == "" || w.ClusterDomain == "" {
+		return to, nil
+	}
+
+	labels := strings.Split(to, ".")
+	switch len(labels) {
+	case 1:
+		if namespace == "" {
+			return to, nil
+		}
+		return fmt.Sprintf("%s.%s.svc.%s", to, namespace, w.ClusterDomain), nil
+	case 2:
+		if w.Client == nil || !expandServiceNames {
+			return to, nil
+		}
+		service := &corev1.Service{}
+		if err := w.Client.Get(ctx, types.NamespacedName{Namespace: labels[1], Name: labels[0]}, service); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return "", errors.Wrapf(err, "failed to get service %s/%s", labels[1], labels[0])
+			}
+			return to, nil
+		}
+		return fmt.Sprintf("%s.%s.svc.%s", labels[0], labels[1], w.ClusterDomain), nil
+	default:
+		return to, nil
+	}
+}
+
 func (w *MasqueradingRuleWebhook) validate(masqueradingRule *v1alpha1.MasqueradingRule) error {
 	_, err := coredns.NewRewriteRule("", masqueradingRule.Spec.From, masqueradingRule.Spec.To)
 	if err != nil {
@@ -59,3 +177,112 @@ func (w *MasqueradingRuleWebhook) validate(masqueradingRule *v1alpha1.Masqueradi
 	}
 	return nil
 }
+
+// check if the given masquerading rule clashes with existing masquerading rules (in any namespace); candidates are looked up
+// through the field indexes (rules with the same source, wildcard rules covering the source, and, if the source is a wildcard,
+// rules covered by the source), and are then checked through a RewriteRuleSet; rules generated for the same resource are not
+// considered as candidates, since they are about to be replaced by the given rule (e.g. rules with generated names, as created
+// by earlier versions of the source controllers)
+func (w *MasqueradingRuleWebhook) checkConflicts(ctx context.Context, masqueradingRule *v1alpha1.MasqueradingRule) (admission.Warnings, error) {
+	if w.Client == nil {
+		return nil, nil
+	}
+
+	controllerUid := getControllerUid(masqueradingRule)
+
+	// note: the field indexes are keyed by the ASCII form of the source, such that Unicode and punycode notations of the same name match
+	from := asciiDnsName(masqueradingRule.Spec.From)
+	candidates := make(map[string]*v1alpha1.MasqueradingRule)
+	lookups := []client.MatchingFields{{masqueradingRuleFromIndex: from}}
+	for _, parent := range getParentDomains(from) {
+		lookups = append(lookups, client.MatchingFields{masqueradingRuleFromIndex: "*." + parent})
+	}
+	if strings.HasPrefix(from, "*.") {
+		lookups = append(lookups, client.MatchingFields{masqueradingRuleFromParentIndex: from[2:]})
+	}
+	for _, lookup := range lookups {
+		masqueradingRuleList := &v1alpha1.MasqueradingRuleList{}
+		if err := w.Client.List(ctx, masqueradingRuleList, lookup); err != nil {
+			return nil, errors.Wrap(err, "failed to list masquerading rules")
+		}
+		for i := range masqueradingRuleList.Items {
+			candidate := &masqueradingRuleList.Items[i]
+			if (candidate.Namespace == masqueradingRule.Namespace && candidate.Name == masqueradingRule.Name) || !candidate.DeletionTimestamp.IsZero() {
+				continue
+			}
+			if controllerUid != "" && candidate.Namespace == masqueradingRule.Namespace && getControllerUid(candidate) == controllerUid {
+				continue
+			}
+			candidates[coredns.RewriteRuleOwner(string(candidate.UID), candidate.Namespace, candidate.Name)] = candidate
+		}
+	}
+
+	ruleset := coredns.NewRewriteRuleSet()
+	owners := make([]string, 0, len(candidates))
+	for owner := range candidates {
+		owners = append(owners, owner)
+	}
+	sort.Strings(owners)
+	for _, owner := range owners {
+		rule, err := coredns.NewRewriteRule(owner, candidates[owner].Spec.From, candidates[owner].Spec.To)
+		if err != nil {
+			continue
+		}
+		// note: candidates clashing with each other are skipped
+		_, _ = ruleset.AddRule(rule)
+	}
+	rule, err := coredns.NewRewriteRule(coredns.RewriteRuleOwner(string(masqueradingRule.UID), masqueradingRule.Namespace, masqueradingRule.Name), from, masqueradingRule.Spec.To)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ruleset.AddRule(rule); err != nil {
+		conflictErr := &coredns.ConflictError{}
+		if !errors.As(err, &conflictErr) {
+			return nil, err
+		}
+		conflictingMasqueradingRule := candidates[conflictErr.ConflictingRule.Owner()]
+		message := fmt.Sprintf("source %s clashes with source %s of masquerading rule %s/%s", masqueradingRule.Spec.From, conflictingMasqueradingRule.Spec.From, conflictingMasqueradingRule.Namespace, conflictingMasqueradingRule.Name)
+		if w.ConflictPolicy == ConflictPolicyWarn {
+			return admission.Warnings{message}, nil
+		}
+		return nil, errors.New(message)
+	}
+	return nil, nil
+}
+
+// return uid of the resource a masquerading rule was generated for; that is, the uid of the controller owner reference, or,
+// if there is none (as for rules created by earlier versions of the source controllers), the value of the controller-uid label
+func getControllerUid(masqueradingRule *v1alpha1.MasqueradingRule) types.UID {
+	if ownerRef := metav1.GetControllerOf(masqueradingRule); ownerRef != nil {
+		return ownerRef.UID
+	}
+	return types.UID(masqueradingRule.Labels[labelControllerUid])
+}
+
+// return parent domains of given DNS name (excluding the name itself, and ignoring a leading wildcard label),
+// e.g. b.example.com and example.com and com for a.b.example.com or *.a.b.example.com
+func getParentDomains(name string) []string {
+	name = strings.TrimPrefix(name, "*.")
+	var parents []string
+	for i := strings.Index(name, "."); i >= 0; i = strings.Index(name, ".") {
+		name = name[i+1:]
+		parents = append(parents, name)
+	}
+	return parents
+}
+
+// return ASCII (punycode) form of a DNS name; invalid names are returned unchanged
+func asciiDnsName(name string) string {
+	if !dnsname.IsUnicode(name) {
+		return name
+	}
+	if s, err := dnsname.ToASCII(name); err == nil {
+		return s
+	}
+	return name
+}
+
+// trim and lowercase DNS name, and strip trailing dot
+func normalizeDnsName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+}
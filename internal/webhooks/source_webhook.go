@@ -0,0 +1,156 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"github.com/sap/dns-masquerading-operator/api/v1alpha1"
+	"github.com/sap/dns-masquerading-operator/internal/coredns"
+	"github.com/sap/go-generics/slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// Function determining the masquerading rules (hosts mapped to rewrite targets) which would be generated for a source object;
+// shall return an error if the masquerading related annotations of the object are invalid
+type SourceTargetsFunc func(ctx context.Context, obj client.Object) (map[string]string, error)
+
+// Validating webhook for source objects (such as services or ingresses), checking the masquerading rules derived from the object
+// for validity, and for conflicts with the rewrite rules currently active in the coredns extension configmap;
+// only rules which are new or changed by the admitted operation are checked
+type SourceWebhook[T client.Object] struct {
+	Log                       logr.Logger
+	Client                    client.Client
+	Targets                   SourceTargetsFunc
+	CorednsConfigMapNamespace string
+	CorednsConfigMapName      string
+	CorednsConfigMapKey       string
+}
+
+func (w *SourceWebhook[T]) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	obj := reflect.New(reflect.TypeFor[T]().Elem()).Interface().(T)
+	return ctrl.NewWebhookManagedBy(mgr, obj).
+		WithValidator(w).
+		Complete()
+}
+
+func (w *SourceWebhook[T]) ValidateCreate(ctx context.Context, obj T) (admission.Warnings, error) {
+	w.Log.Info("validate create", "namespace", obj.GetNamespace(), "name", obj.GetName())
+
+	return w.validate(ctx, nil, obj)
+}
+
+func (w *SourceWebhook[T]) ValidateUpdate(ctx context.Context, oldObj T, obj T) (admission.Warnings, error) {
+	w.Log.Info("validate update", "namespace", obj.GetNamespace(), "name", obj.GetName())
+
+	// note: objects being deleted must not be blocked (e.g. when finalizers are removed)
+	if !obj.GetDeletionTimestamp().IsZero() {
+		return nil, nil
+	}
+	return w.validate(ctx, oldObj, obj)
+}
+
+func (w *SourceWebhook[T]) ValidateDelete(ctx context.Context, obj T) (admission.Warnings, error) {
+	w.Log.Info("validate delete", "namespace", obj.GetNamespace(), "name", obj.GetName())
+
+	return nil, nil
+}
+
+func (w *SourceWebhook[T]) validate(ctx context.Context, oldObj client.Object, obj client.Object) (admission.Warnings, error) {
+	targets, err := w.Targets(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+	var oldTargets map[string]string
+	if oldObj != nil {
+		// note: if the old object cannot be evaluated, consider all targets as changed
+		oldTargets, _ = w.Targets(ctx, oldObj)
+	}
+
+	var changedHosts []string
+	var unchangedHosts []string
+	for host, to := range targets {
+		if oldTo, ok := oldTargets[host]; ok && oldTo == to {
+			unchangedHosts = append(unchangedHosts, host)
+		} else {
+			changedHosts = append(changedHosts, host)
+		}
+	}
+	if len(changedHosts) == 0 {
+		return nil, nil
+	}
+	sort.Strings(changedHosts)
+	sort.Strings(unchangedHosts)
+
+	ruleset, warnings, err := w.getRuleSet(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	// note: unchanged rules are added first (ignoring errors), such that conflicts are reported for the changed rules only
+	for _, host := range append(unchangedHosts, changedHosts...) {
+		changed := !slices.Contains(unchangedHosts, host)
+		rule, err := coredns.NewRewriteRule(fmt.Sprintf("%s/%s (%s)", obj.GetNamespace(), obj.GetName(), host), host, targets[host])
+		if err != nil {
+			if changed {
+				problems = append(problems, fmt.Sprintf("invalid rule %s -> %s: %s", host, targets[host], err))
+			}
+			continue
+		}
+		if ruleset == nil {
+			continue
+		}
+		if _, err := ruleset.AddRule(rule); err != nil && changed {
+			problems = append(problems, err.Error())
+		}
+	}
+	if len(problems) > 0 {
+		return warnings, fmt.Errorf("invalid masquerading configuration: %s", strings.Join(problems, "; "))
+	}
+	return warnings, nil
+}
+
+// load the rewrite rules currently active in the coredns extension configmap, except for the rules generated for given object;
+// returns nil (and a warning) if the configmap cannot be parsed
+func (w *SourceWebhook[T]) getRuleSet(ctx context.Context, obj client.Object) (*coredns.RewriteRuleSet, admission.Warnings, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := w.Client.Get(ctx, types.NamespacedName{Namespace: w.CorednsConfigMapNamespace, Name: w.CorednsConfigMapName}, configMap); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return nil, nil, errors.Wrapf(err, "failed to get configmap %s/%s", w.CorednsConfigMapNamespace, w.CorednsConfigMapName)
+		}
+		return coredns.NewRewriteRuleSet(), nil, nil
+	}
+	ruleset, err := coredns.ParseRewriteRuleSet(configMap.Data[w.CorednsConfigMapKey])
+	if err != nil {
+		return nil, admission.Warnings{fmt.Sprintf("skipping conflict check; error loading rewrite rules from configmap %s/%s (key: %s): %s", configMap.Namespace, configMap.Name, w.CorednsConfigMapKey, err)}, nil
+	}
+
+	if obj.GetUID() != "" {
+		masqueradingRuleList := &v1alpha1.MasqueradingRuleList{}
+		if err := w.Client.List(ctx, masqueradingRuleList, client.InNamespace(obj.GetNamespace())); err != nil {
+			return nil, nil, errors.Wrap(err, "failed to list masquerading rules")
+		}
+		for _, masqueradingRule := range masqueradingRuleList.Items {
+			// note: rules created by earlier versions of the operator are linked to the object by label only
+			if getControllerUid(&masqueradingRule) == obj.GetUID() {
+				ruleset.RemoveRule(coredns.RewriteRuleOwner(string(masqueradingRule.UID), masqueradingRule.Namespace, masqueradingRule.Name))
+			}
+		}
+	}
+	return ruleset, nil, nil
+}
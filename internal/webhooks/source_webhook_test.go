@@ -0,0 +1,89 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhooks
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/sap/dns-masquerading-operator/api/v1alpha1"
+	"github.com/sap/dns-masquerading-operator/internal/coredns"
+)
+
+func TestSourceWebhookGetRuleSet(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("error building scheme: %s", err)
+	}
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("error building scheme: %s", err)
+	}
+
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test", UID: types.UID("test-uid")}}
+	newRule := func(name string, uid string, from string, owned bool) *v1alpha1.MasqueradingRule {
+		masqueradingRule := &v1alpha1.MasqueradingRule{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID(name + "-uid"), Labels: map[string]string{labelControllerUid: uid}},
+			Spec:       v1alpha1.MasqueradingRuleSpec{From: from, To: "1.2.3.4"},
+		}
+		if owned {
+			masqueradingRule.OwnerReferences = []metav1.OwnerReference{{APIVersion: "v1", Kind: "Service", Name: "test", UID: types.UID(uid), Controller: &[]bool{true}[0]}}
+		}
+		return masqueradingRule
+	}
+	masqueradingRules := []*v1alpha1.MasqueradingRule{
+		newRule("owned", "test-uid", "owned.example.io", true),
+		newRule("legacy", "test-uid", "legacy.example.io", false),
+		newRule("foreign", "other-uid", "foreign.example.io", true),
+	}
+
+	ruleset := coredns.NewRewriteRuleSet()
+	for _, masqueradingRule := range masqueradingRules {
+		rule, err := coredns.NewRewriteRule(coredns.RewriteRuleOwner(string(masqueradingRule.UID), masqueradingRule.Namespace, masqueradingRule.Name), masqueradingRule.Spec.From, masqueradingRule.Spec.To)
+		if err != nil {
+			t.Fatalf("got unexpected error: %s", err)
+		}
+		if _, err := ruleset.AddRule(rule); err != nil {
+			t.Fatalf("got unexpected error: %s", err)
+		}
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "coredns-custom"},
+		Data:       map[string]string{"test.override": ruleset.String()},
+	}
+
+	builder := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap)
+	for _, masqueradingRule := range masqueradingRules {
+		builder = builder.WithObjects(masqueradingRule)
+	}
+	w := &SourceWebhook[*corev1.Service]{
+		Client:                    builder.Build(),
+		CorednsConfigMapNamespace: configMap.Namespace,
+		CorednsConfigMapName:      configMap.Name,
+		CorednsConfigMapKey:       "test.override",
+	}
+
+	ruleset, warnings, err := w.getRuleSet(context.TODO(), service)
+	if err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	if len(warnings) > 0 {
+		t.Errorf("got unexpected warnings: %v", warnings)
+	}
+	for _, masqueradingRule := range masqueradingRules {
+		owner := coredns.RewriteRuleOwner(string(masqueradingRule.UID), masqueradingRule.Namespace, masqueradingRule.Name)
+		expected := masqueradingRule.Name == "foreign"
+		if found := ruleset.GetRule(owner) != nil; found != expected {
+			t.Errorf("rule of masquerading rule %s: got found %t, want %t", masqueradingRule.Name, found, expected)
+		}
+	}
+}
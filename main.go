@@ -6,30 +6,45 @@ SPDX-License-Identifier: Apache-2.0
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"net"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	istionetworkingv1beta1 "istio.io/client-go/pkg/apis/networking/v1beta1"
 	istioscheme "istio.io/client-go/pkg/clientset/versioned/scheme"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	dnsv1alpha1 "github.com/sap/dns-masquerading-operator/api/v1alpha1"
+	dnsv1beta1 "github.com/sap/dns-masquerading-operator/api/v1beta1"
 	"github.com/sap/dns-masquerading-operator/internal/controllers"
 	"github.com/sap/dns-masquerading-operator/internal/coredns"
 	"github.com/sap/dns-masquerading-operator/internal/webhooks"
@@ -52,8 +67,10 @@ var (
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(istioscheme.AddToScheme(scheme))
+	utilruntime.Must(gatewayv1.Install(scheme))
 
 	utilruntime.Must(dnsv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(dnsv1beta1.AddToScheme(scheme))
 }
 
 func main() {
@@ -66,9 +83,30 @@ func main() {
 	var corednsConfigMapNamespace string
 	var corednsConfigMapName string
 	var corednsConfigMapKey string
+	var clusterDomain string
 	var enableServiceController bool
 	var enableIngressController bool
 	var enableIstioGatewayController bool
+	var enableIstioVirtualServiceController bool
+	var enableIstioServiceEntryController bool
+	var enableGatewayAPIController bool
+	var enableGardenerDNSEntryController bool
+	var enableExternalDNSEndpointController bool
+	var ingressClasses string
+	var istioGatewaySelectors string
+	var gatewayAPIClasses string
+	var genericSourcesConfigPath string
+	var dnsVerificationMode string
+	var dnsQueryTimeout time.Duration
+	var dnsQueryAttempts int
+	var dnsCheckTimeout time.Duration
+	var conflictPolicy string
+	var verifyDeletion bool
+	var deletionVerificationTimeout time.Duration
+	var filterSweepInterval time.Duration
+	var conversionWebhookService string
+	var conversionWebhookServicePort int
+	sourceFilterFlagsByKind := make(map[string]*sourceFilterFlags)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.StringVar(&webhookAddr, "webhook-bind-address", ":9443", "The address the webhook endpoint binds to.")
@@ -78,9 +116,36 @@ func main() {
 	flag.StringVar(&corednsConfigMapNamespace, "coredns-configmap-namespace", "kube-system", "The namespace of the coredns extension configmap where this controller stores the rewrite rules")
 	flag.StringVar(&corednsConfigMapName, "coredns-configmap-name", "coredns-custom", "The name of the coredns extension configmap where this controller stores the rewrite rules")
 	flag.StringVar(&corednsConfigMapKey, "coredns-configmap-key", "masquerading-operator.override", "The key in the coredns extension configmap where this controller stores the rewrite rules")
+	flag.StringVar(&clusterDomain, "cluster-domain", "", "The cluster domain (e.g. cluster.local); auto-detected if empty")
 	flag.BoolVar(&enableServiceController, "enable-service-controller", false, "Whether to generate masquerading rules based on services as a source")
 	flag.BoolVar(&enableIngressController, "enable-ingress-controller", false, "Whether to generate masquerading rules based on ingresses as a source")
 	flag.BoolVar(&enableIstioGatewayController, "enable-istiogateway-controller", false, "Whether to generate masquerading rules based on istio gateways as a source")
+	flag.BoolVar(&enableIstioVirtualServiceController, "enable-istiovirtualservice-controller", false, "Whether to generate masquerading rules based on istio virtual services as a source")
+	flag.BoolVar(&enableIstioServiceEntryController, "enable-istioserviceentry-controller", false, "Whether to generate masquerading rules based on istio service entries as a source")
+	flag.BoolVar(&enableGatewayAPIController, "enable-gatewayapi-controller", false, "Whether to generate masquerading rules based on gateway api gateways and routes (HTTPRoute, GRPCRoute, TLSRoute) as a source")
+	flag.BoolVar(&enableGardenerDNSEntryController, "enable-gardenerdnsentry-controller", false, "Whether to generate masquerading rules based on gardener dns entries as a source")
+	flag.BoolVar(&enableExternalDNSEndpointController, "enable-externaldnsendpoint-controller", false, "Whether to generate masquerading rules based on external-dns dns endpoints as a source")
+	flag.StringVar(&ingressClasses, "ingress-classes", "", "Comma-separated list of ingress classes (of the form class[:target]) handled by the ingress controller; the optional target is used as default target for ingresses of that class")
+	flag.StringVar(&istioGatewaySelectors, "istiogateway-selectors", "", "Comma-separated list of selector labels (of the form key=value[:target]) handled by the istio gateway controller; gateways match if their selector contains one of the labels; the optional target is used as default target")
+	flag.StringVar(&gatewayAPIClasses, "gatewayapi-classes", "", "Comma-separated list of gateway classes (of the form class[:target]) handled by the gateway api controller; routes match if one of their parent gateways matches; the optional target is used as default target")
+	flag.StringVar(&genericSourcesConfigPath, "generic-sources-config", "", "Path to a file describing additional resource types (group, version, kind, and jsonpath expressions for hosts and target) to be used as sources for masquerading rules")
+	flag.StringVar(&dnsVerificationMode, "dns-verification-mode", string(coredns.VerificationModeAddresses), "How to verify that masquerading rules are active; one of 'addresses' (compare resolved addresses) or 'rewrite' (inspect the answer for evidence of the rewrite)")
+	flag.DurationVar(&dnsQueryTimeout, "dns-query-timeout", 2*time.Second, "Timeout of a single DNS query attempt when verifying masquerading rules")
+	flag.IntVar(&dnsQueryAttempts, "dns-query-attempts", 3, "Number of attempts per DNS query when verifying masquerading rules")
+	flag.DurationVar(&dnsCheckTimeout, "dns-check-timeout", 30*time.Second, "Overall timeout for verifying a single masquerading rule against all nameserver instances")
+	flag.StringVar(&conflictPolicy, "masqueradingrule-conflict-policy", string(webhooks.ConflictPolicyReject), "How to handle masquerading rules clashing with existing rules at admission time; one of 'reject' or 'warn'")
+	flag.BoolVar(&verifyDeletion, "verify-deletion", false, "Whether to wait until DNS no longer serves deleted masquerading rules before releasing them")
+	flag.DurationVar(&deletionVerificationTimeout, "deletion-verification-timeout", 2*time.Minute, "Maximum time to wait for deleted masquerading rules to disappear from DNS (if --verify-deletion is set)")
+	flag.DurationVar(&filterSweepInterval, "filter-sweep-interval", 10*time.Minute, "Interval for removing masquerading rules of objects which no longer match the namespace or label filter of their source controller")
+	flag.StringVar(&conversionWebhookService, "conversion-webhook-service", "", "Service (of the form [namespace/]name) exposing the webhook server; if set, the conversion webhook of the MasqueradingRule custom resource definition is pointed to this service (and the ca bundle of the webhook server); the namespace defaults to the controller namespace when running in-cluster")
+	flag.IntVar(&conversionWebhookServicePort, "conversion-webhook-service-port", 443, "Port of the service exposing the webhook server (if --conversion-webhook-service is set)")
+	for _, kind := range sourceFilterKinds {
+		f := &sourceFilterFlags{}
+		flag.StringVar(&f.namespaces, kind+"-namespaces", "", fmt.Sprintf("Comma-separated list of namespaces watched by the %s controller; defaults to all namespaces", kind))
+		flag.StringVar(&f.excludedNamespaces, kind+"-excluded-namespaces", "", fmt.Sprintf("Comma-separated list of namespaces ignored by the %s controller (if --%s-namespaces is not set)", kind, kind))
+		flag.StringVar(&f.selector, kind+"-selector", "", fmt.Sprintf("Label selector restricting the objects handled by the %s controller", kind))
+		sourceFilterFlagsByKind[kind] = f
+	}
 	opts := zap.Options{
 		Development: false,
 	}
@@ -101,6 +166,77 @@ func main() {
 		os.Exit(1)
 	}
 
+	if dnsVerificationMode != string(coredns.VerificationModeAddresses) && dnsVerificationMode != string(coredns.VerificationModeRewrite) {
+		setupLog.Error(nil, "invalid command line parameter", "flag", "--dns-verification-mode", "value", dnsVerificationMode)
+		os.Exit(1)
+	}
+
+	if conflictPolicy != string(webhooks.ConflictPolicyReject) && conflictPolicy != string(webhooks.ConflictPolicyWarn) {
+		setupLog.Error(nil, "invalid command line parameter", "flag", "--masqueradingrule-conflict-policy", "value", conflictPolicy)
+		os.Exit(1)
+	}
+
+	sourceFilters := make(map[string]*controllers.SourceFilter)
+	for _, kind := range sourceFilterKinds {
+		f := sourceFilterFlagsByKind[kind]
+		sourceFilter, err := controllers.ParseSourceFilter(f.namespaces, f.excludedNamespaces, f.selector)
+		if err != nil {
+			setupLog.Error(err, "invalid command line parameter", "flag", "--"+kind+"-*")
+			os.Exit(1)
+		}
+		sourceFilters[kind] = sourceFilter
+	}
+
+	classFilters := make(map[string]controllers.ClassFilter)
+	for flagName, value := range map[string]string{"ingress-classes": ingressClasses, "istiogateway-selectors": istioGatewaySelectors, "gatewayapi-classes": gatewayAPIClasses} {
+		classFilter, err := controllers.ParseClassFilter(value)
+		if err != nil {
+			setupLog.Error(err, "invalid command line parameter", "flag", "--"+flagName)
+			os.Exit(1)
+		}
+		classFilters[flagName] = classFilter
+	}
+
+	// note: services and gateway api gateways are not restricted in the cache, since they are read by other controllers as well;
+	// masquerading rules of objects dropping out of a restricted cache are cleaned up by the filter sweeper
+	cacheByObject := make(map[client.Object]cache.ByObject)
+	sweptFilters := make(map[schema.GroupKind]*controllers.SourceFilter)
+	restrictCache := func(enabled bool, kind string, objs ...client.Object) {
+		if !enabled || sourceFilters[kind] == nil {
+			return
+		}
+		for _, obj := range objs {
+			cacheByObject[obj] = sourceFilters[kind].CacheConfig()
+			gvk, err := apiutil.GVKForObject(obj, scheme)
+			if err != nil {
+				setupLog.Error(err, "unable to determine group version kind", "kind", kind)
+				os.Exit(1)
+			}
+			sweptFilters[gvk.GroupKind()] = sourceFilters[kind]
+		}
+	}
+	restrictCache(enableIngressController, "ingress", &networkingv1.Ingress{})
+	restrictCache(enableIstioGatewayController, "istiogateway", &istionetworkingv1beta1.Gateway{})
+	restrictCache(enableIstioVirtualServiceController, "istiovirtualservice", &istionetworkingv1beta1.VirtualService{})
+	restrictCache(enableIstioServiceEntryController, "istioserviceentry", &istionetworkingv1beta1.ServiceEntry{})
+	restrictCache(enableGatewayAPIController, "gatewayapi", &gatewayv1.HTTPRoute{}, &gatewayv1.GRPCRoute{}, &gatewayv1.TLSRoute{})
+	restrictCache(enableGardenerDNSEntryController, "gardenerdnsentry", newUnstructured(controllers.DNSEntryGroupVersionKind))
+	restrictCache(enableExternalDNSEndpointController, "externaldnsendpoint", newUnstructured(controllers.DNSEndpointGroupVersionKind))
+
+	var conversionWebhookServiceNamespace string
+	var conversionWebhookServiceName string
+	if conversionWebhookService != "" {
+		if namespace, name, ok := strings.Cut(conversionWebhookService, "/"); ok {
+			conversionWebhookServiceNamespace, conversionWebhookServiceName = namespace, name
+		} else if inCluster {
+			conversionWebhookServiceNamespace, conversionWebhookServiceName = inClusterNamespace, conversionWebhookService
+		}
+		if conversionWebhookServiceNamespace == "" || conversionWebhookServiceName == "" {
+			setupLog.Error(nil, "invalid command line parameter", "flag", "--conversion-webhook-service", "value", conversionWebhookService)
+			os.Exit(1)
+		}
+	}
+
 	if enableLeaderElection && leaderElectionNamespace == "" {
 		if inCluster {
 			leaderElectionNamespace = inClusterNamespace
@@ -112,8 +248,13 @@ func main() {
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme: scheme,
+		Cache: cache.Options{
+			ByObject: cacheByObject,
+		},
 		Client: client.Options{
 			Cache: &client.CacheOptions{
+				// note: required for generic sources (and other sources handled as unstructured objects), to read them from the cache
+				Unstructured: true,
 				DisableFor: []client.Object{
 					&dnsv1alpha1.MasqueradingRule{},
 					&corev1.ConfigMap{},
@@ -139,54 +280,279 @@ func main() {
 		os.Exit(1)
 	}
 
+	if clusterDomain == "" {
+		clusterDomain, err = coredns.DetectClusterDomain(context.TODO(), mgr.GetAPIReader(), inCluster)
+		if err != nil {
+			setupLog.Error(err, "error detecting cluster domain; using default", "clusterDomain", coredns.DefaultClusterDomain)
+			clusterDomain = coredns.DefaultClusterDomain
+		} else if clusterDomain == "" {
+			clusterDomain = coredns.DefaultClusterDomain
+			setupLog.Info("unable to detect cluster domain; using default", "clusterDomain", clusterDomain)
+		} else {
+			setupLog.Info("detected cluster domain", "clusterDomain", clusterDomain)
+		}
+	}
+
 	if enableServiceController {
-		if err = (&controllers.ServiceReconciler{
-			Client: mgr.GetClient(),
-			Scheme: mgr.GetScheme(),
-		}).SetupWithManager(mgr); err != nil {
+		serviceReconciler := &controllers.ServiceReconciler{
+			Client:        mgr.GetClient(),
+			Scheme:        mgr.GetScheme(),
+			Recorder:      mgr.GetEventRecorderFor(controllerName),
+			Filter:        sourceFilters["service"],
+			ClusterDomain: clusterDomain,
+		}
+		if err = serviceReconciler.SetupWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create controller", "controller", "Service")
 			os.Exit(1)
 		}
+		if err = (&webhooks.SourceWebhook[*corev1.Service]{
+			Log:                       ctrllog.Log.WithName("service-resource"),
+			Client:                    mgr.GetClient(),
+			Targets:                   serviceReconciler.GetTargets,
+			CorednsConfigMapNamespace: corednsConfigMapNamespace,
+			CorednsConfigMapName:      corednsConfigMapName,
+			CorednsConfigMapKey:       corednsConfigMapKey,
+		}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Service")
+			os.Exit(1)
+		}
 	}
 
 	if enableIngressController {
-		if err = (&controllers.IngressReconciler{
-			Client: mgr.GetClient(),
-			Scheme: mgr.GetScheme(),
-		}).SetupWithManager(mgr); err != nil {
+		ingressReconciler := &controllers.IngressReconciler{
+			Client:        mgr.GetClient(),
+			Scheme:        mgr.GetScheme(),
+			Recorder:      mgr.GetEventRecorderFor(controllerName),
+			Filter:        sourceFilters["ingress"],
+			Classes:       classFilters["ingress-classes"],
+			ClusterDomain: clusterDomain,
+		}
+		if err = ingressReconciler.SetupWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create controller", "controller", "Ingress")
 			os.Exit(1)
 		}
+		if err = (&webhooks.SourceWebhook[*networkingv1.Ingress]{
+			Log:                       ctrllog.Log.WithName("ingress-resource"),
+			Client:                    mgr.GetClient(),
+			Targets:                   ingressReconciler.GetTargets,
+			CorednsConfigMapNamespace: corednsConfigMapNamespace,
+			CorednsConfigMapName:      corednsConfigMapName,
+			CorednsConfigMapKey:       corednsConfigMapKey,
+		}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Ingress")
+			os.Exit(1)
+		}
 	}
 
 	if enableIstioGatewayController {
-		if err = (&controllers.GatewayReconciler{
-			Client: mgr.GetClient(),
-			Scheme: mgr.GetScheme(),
-		}).SetupWithManager(mgr); err != nil {
+		gatewayReconciler := &controllers.GatewayReconciler{
+			Client:        mgr.GetClient(),
+			Scheme:        mgr.GetScheme(),
+			Recorder:      mgr.GetEventRecorderFor(controllerName),
+			Filter:        sourceFilters["istiogateway"],
+			Classes:       classFilters["istiogateway-selectors"],
+			ClusterDomain: clusterDomain,
+		}
+		if err = gatewayReconciler.SetupWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create controller", "controller", "Gateway")
 			os.Exit(1)
 		}
+		if err = (&webhooks.SourceWebhook[*istionetworkingv1beta1.Gateway]{
+			Log:                       ctrllog.Log.WithName("gateway-resource"),
+			Client:                    mgr.GetClient(),
+			Targets:                   gatewayReconciler.GetTargets,
+			CorednsConfigMapNamespace: corednsConfigMapNamespace,
+			CorednsConfigMapName:      corednsConfigMapName,
+			CorednsConfigMapKey:       corednsConfigMapKey,
+		}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Gateway")
+			os.Exit(1)
+		}
+	}
+
+	if enableIstioVirtualServiceController {
+		if err = (&controllers.VirtualServiceReconciler{
+			Client:        mgr.GetClient(),
+			Scheme:        mgr.GetScheme(),
+			Recorder:      mgr.GetEventRecorderFor(controllerName),
+			Filter:        sourceFilters["istiovirtualservice"],
+			ClusterDomain: clusterDomain,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "VirtualService")
+			os.Exit(1)
+		}
 	}
 
+	if enableIstioServiceEntryController {
+		if err = (&controllers.ServiceEntryReconciler{
+			Client:        mgr.GetClient(),
+			Scheme:        mgr.GetScheme(),
+			Recorder:      mgr.GetEventRecorderFor(controllerName),
+			Filter:        sourceFilters["istioserviceentry"],
+			ClusterDomain: clusterDomain,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "ServiceEntry")
+			os.Exit(1)
+		}
+	}
+
+	if enableGatewayAPIController {
+		if err = (&controllers.KubernetesGatewayReconciler{
+			Client:        mgr.GetClient(),
+			Scheme:        mgr.GetScheme(),
+			Recorder:      mgr.GetEventRecorderFor(controllerName),
+			Filter:        sourceFilters["gatewayapi"],
+			Classes:       classFilters["gatewayapi-classes"],
+			ClusterDomain: clusterDomain,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "KubernetesGateway")
+			os.Exit(1)
+		}
+		for _, route := range []client.Object{&gatewayv1.HTTPRoute{}, &gatewayv1.GRPCRoute{}, &gatewayv1.TLSRoute{}} {
+			// route kinds are optional (e.g. TLSRoute is only part of the experimental channel); skip kinds not served by the cluster
+			served, err := isServed(mgr, route)
+			if err != nil {
+				setupLog.Error(err, "unable to check availability of route kind", "controller", fmt.Sprintf("%T", route))
+				os.Exit(1)
+			}
+			if !served {
+				setupLog.Info("route kind not served by cluster; skipping controller", "controller", fmt.Sprintf("%T", route))
+				continue
+			}
+			if err = (&controllers.RouteReconciler{
+				Client:        mgr.GetClient(),
+				Scheme:        mgr.GetScheme(),
+				Recorder:      mgr.GetEventRecorderFor(controllerName),
+				Filter:        sourceFilters["gatewayapi"],
+				Classes:       classFilters["gatewayapi-classes"],
+				ClusterDomain: clusterDomain,
+				Route:         route,
+			}).SetupWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create controller", "controller", fmt.Sprintf("%T", route))
+				os.Exit(1)
+			}
+		}
+	}
+
+	if enableGardenerDNSEntryController {
+		if err = (&controllers.DNSEntryReconciler{
+			Client:   mgr.GetClient(),
+			Scheme:   mgr.GetScheme(),
+			Recorder: mgr.GetEventRecorderFor(controllerName),
+			Filter:   sourceFilters["gardenerdnsentry"],
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "DNSEntry")
+			os.Exit(1)
+		}
+	}
+
+	if enableExternalDNSEndpointController {
+		if err = (&controllers.DNSEndpointReconciler{
+			Client:   mgr.GetClient(),
+			Scheme:   mgr.GetScheme(),
+			Recorder: mgr.GetEventRecorderFor(controllerName),
+			Filter:   sourceFilters["externaldnsendpoint"],
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "DNSEndpoint")
+			os.Exit(1)
+		}
+	}
+
+	if genericSourcesConfigPath != "" {
+		genericSourcesConfig, err := controllers.LoadGenericSourcesConfig(genericSourcesConfigPath)
+		if err != nil {
+			setupLog.Error(err, "unable to load generic sources config")
+			os.Exit(1)
+		}
+		for _, source := range genericSourcesConfig.Sources {
+			if err = (&controllers.GenericReconciler{
+				Client:   mgr.GetClient(),
+				Scheme:   mgr.GetScheme(),
+				Recorder: mgr.GetEventRecorderFor(controllerName),
+				Source:   source,
+			}).SetupWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create controller", "controller", source.GroupVersionKind().String())
+				os.Exit(1)
+			}
+		}
+	}
+
+	if len(sweptFilters) > 0 {
+		if err := mgr.Add(&controllers.FilterSweeper{
+			Client:   mgr.GetClient(),
+			Reader:   mgr.GetAPIReader(),
+			Filters:  sweptFilters,
+			Interval: filterSweepInterval,
+		}); err != nil {
+			setupLog.Error(err, "unable to add filter sweeper to manager")
+			os.Exit(1)
+		}
+	}
+
+	resolver := coredns.NewResolver(mgr.GetClient(), mgr.GetConfig(), inCluster, coredns.ResolverOptions{
+		VerificationMode: coredns.VerificationMode(dnsVerificationMode),
+		QueryTimeout:     dnsQueryTimeout,
+		QueryAttempts:    dnsQueryAttempts,
+		CheckTimeout:     dnsCheckTimeout,
+	})
+	// release pooled port-forwards of the resolver when the manager stops
+	if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		resolver.Close()
+		return nil
+	})); err != nil {
+		setupLog.Error(err, "unable to add resolver cleanup to manager")
+		os.Exit(1)
+	}
 	if err = (&controllers.MasqueradingRuleReconciler{
-		Client:                    mgr.GetClient(),
-		Scheme:                    mgr.GetScheme(),
-		Recorder:                  mgr.GetEventRecorderFor(controllerName),
-		CorednsConfigMapNamespace: corednsConfigMapNamespace,
-		CorednsConfigMapName:      corednsConfigMapName,
-		CorednsConfigMapKey:       corednsConfigMapKey,
-		Resolver:                  coredns.NewResolver(mgr.GetClient(), mgr.GetConfig(), inCluster),
+		Client:                      mgr.GetClient(),
+		Scheme:                      mgr.GetScheme(),
+		Recorder:                    mgr.GetEventRecorderFor(controllerName),
+		CorednsConfigMapNamespace:   corednsConfigMapNamespace,
+		CorednsConfigMapName:        corednsConfigMapName,
+		CorednsConfigMapKey:         corednsConfigMapKey,
+		Resolver:                    resolver,
+		VerifyDeletion:              verifyDeletion,
+		DeletionVerificationTimeout: deletionVerificationTimeout,
+		ClusterDomain:               clusterDomain,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "MasqueradingRule")
 		os.Exit(1)
 	}
 	if err = (&webhooks.MasqueradingRuleWebhook{
-		Log: ctrllog.Log.WithName("masqueradingrule-resource"),
+		Log:            ctrllog.Log.WithName("masqueradingrule-resource"),
+		Client:         mgr.GetCache(),
+		ConflictPolicy: webhooks.ConflictPolicy(conflictPolicy),
+		ClusterDomain:  clusterDomain,
 	}).SetupWebhookWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create webhook", "webhook", "MasqueradingRule")
 		os.Exit(1)
 	}
+	// note: v1alpha1 is the conversion hub (and storage version), so registering the v1beta1 spoke enables the conversion webhook
+	if err = ctrl.NewWebhookManagedBy(mgr, &dnsv1beta1.MasqueradingRule{}).Complete(); err != nil {
+		setupLog.Error(err, "unable to create conversion webhook", "webhook", "MasqueradingRule")
+		os.Exit(1)
+	}
+
+	if conversionWebhookService != "" {
+		certDir := webhookCertDir
+		if certDir == "" {
+			// note: this is the default of the controller-runtime webhook server
+			certDir = filepath.Join(os.TempDir(), "k8s-webhook-server", "serving-certs")
+		}
+		if err := mgr.Add(&webhooks.ConversionWebhookRegistrar{
+			Client:                    mgr.GetClient(),
+			CustomResourceDefinitions: []string{"masqueradingrules.dns.cs.sap.com"},
+			ServiceNamespace:          conversionWebhookServiceNamespace,
+			ServiceName:               conversionWebhookServiceName,
+			ServicePort:               int32(conversionWebhookServicePort),
+			CertDir:                   certDir,
+			Interval:                  10 * time.Minute,
+		}); err != nil {
+			setupLog.Error(err, "unable to add conversion webhook registrar")
+			os.Exit(1)
+		}
+	}
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
@@ -204,6 +570,37 @@ func main() {
 	}
 }
 
+// source controllers supporting namespace and label selector filters (flag prefixes)
+var sourceFilterKinds = []string{"service", "ingress", "istiogateway", "istiovirtualservice", "istioserviceentry", "gatewayapi", "gardenerdnsentry", "externaldnsendpoint"}
+
+// command line flags describing the filter of a source controller
+type sourceFilterFlags struct {
+	namespaces         string
+	excludedNamespaces string
+	selector           string
+}
+
+func newUnstructured(gvk schema.GroupVersionKind) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	return obj
+}
+
+// check if the kind of given object is served by the cluster
+func isServed(mgr manager.Manager, obj client.Object) (bool, error) {
+	gvk, err := mgr.GetClient().GroupVersionKindFor(obj)
+	if err != nil {
+		return false, err
+	}
+	if _, err := mgr.GetRESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+		if meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
 func parseAddress(address string) (string, int, error) {
 	host, p, err := net.SplitHostPort(address)
 	if err != nil {
@@ -12,6 +12,7 @@ import (
 	http "net/http"
 
 	dnsv1alpha1 "github.com/sap/dns-masquerading-operator/pkg/client/clientset/versioned/typed/dns.cs.sap.com/v1alpha1"
+	dnsv1beta1 "github.com/sap/dns-masquerading-operator/pkg/client/clientset/versioned/typed/dns.cs.sap.com/v1beta1"
 	discovery "k8s.io/client-go/discovery"
 	rest "k8s.io/client-go/rest"
 	flowcontrol "k8s.io/client-go/util/flowcontrol"
@@ -20,12 +21,14 @@ import (
 type Interface interface {
 	Discovery() discovery.DiscoveryInterface
 	DnsV1alpha1() dnsv1alpha1.DnsV1alpha1Interface
+	DnsV1beta1() dnsv1beta1.DnsV1beta1Interface
 }
 
 // Clientset contains the clients for groups.
 type Clientset struct {
 	*discovery.DiscoveryClient
 	dnsV1alpha1 *dnsv1alpha1.DnsV1alpha1Client
+	dnsV1beta1  *dnsv1beta1.DnsV1beta1Client
 }
 
 // DnsV1alpha1 retrieves the DnsV1alpha1Client
@@ -33,6 +36,11 @@ func (c *Clientset) DnsV1alpha1() dnsv1alpha1.DnsV1alpha1Interface {
 	return c.dnsV1alpha1
 }
 
+// DnsV1beta1 retrieves the DnsV1beta1Client
+func (c *Clientset) DnsV1beta1() dnsv1beta1.DnsV1beta1Interface {
+	return c.dnsV1beta1
+}
+
 // Discovery retrieves the DiscoveryClient
 func (c *Clientset) Discovery() discovery.DiscoveryInterface {
 	if c == nil {
@@ -81,6 +89,10 @@ func NewForConfigAndClient(c *rest.Config, httpClient *http.Client) (*Clientset,
 	if err != nil {
 		return nil, err
 	}
+	cs.dnsV1beta1, err = dnsv1beta1.NewForConfigAndClient(&configShallowCopy, httpClient)
+	if err != nil {
+		return nil, err
+	}
 
 	cs.DiscoveryClient, err = discovery.NewDiscoveryClientForConfigAndClient(&configShallowCopy, httpClient)
 	if err != nil {
@@ -103,6 +115,7 @@ func NewForConfigOrDie(c *rest.Config) *Clientset {
 func New(c rest.Interface) *Clientset {
 	var cs Clientset
 	cs.dnsV1alpha1 = dnsv1alpha1.New(c)
+	cs.dnsV1beta1 = dnsv1beta1.New(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClient(c)
 	return &cs
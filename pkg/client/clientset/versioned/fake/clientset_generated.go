@@ -11,6 +11,8 @@ import (
 	clientset "github.com/sap/dns-masquerading-operator/pkg/client/clientset/versioned"
 	dnsv1alpha1 "github.com/sap/dns-masquerading-operator/pkg/client/clientset/versioned/typed/dns.cs.sap.com/v1alpha1"
 	fakednsv1alpha1 "github.com/sap/dns-masquerading-operator/pkg/client/clientset/versioned/typed/dns.cs.sap.com/v1alpha1/fake"
+	dnsv1beta1 "github.com/sap/dns-masquerading-operator/pkg/client/clientset/versioned/typed/dns.cs.sap.com/v1beta1"
+	fakednsv1beta1 "github.com/sap/dns-masquerading-operator/pkg/client/clientset/versioned/typed/dns.cs.sap.com/v1beta1/fake"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
@@ -88,3 +90,8 @@ var (
 func (c *Clientset) DnsV1alpha1() dnsv1alpha1.DnsV1alpha1Interface {
 	return &fakednsv1alpha1.FakeDnsV1alpha1{Fake: &c.Fake}
 }
+
+// DnsV1beta1 retrieves the DnsV1beta1Client
+func (c *Clientset) DnsV1beta1() dnsv1beta1.DnsV1beta1Interface {
+	return &fakednsv1beta1.FakeDnsV1beta1{Fake: &c.Fake}
+}
@@ -9,6 +9,7 @@ package scheme
 
 import (
 	dnsv1alpha1 "github.com/sap/dns-masquerading-operator/api/v1alpha1"
+	dnsv1beta1 "github.com/sap/dns-masquerading-operator/api/v1beta1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -21,6 +22,7 @@ var Codecs = serializer.NewCodecFactory(Scheme)
 var ParameterCodec = runtime.NewParameterCodec(Scheme)
 var localSchemeBuilder = runtime.SchemeBuilder{
 	dnsv1alpha1.AddToScheme,
+	dnsv1beta1.AddToScheme,
 }
 
 // AddToScheme adds all types of this clientset into the given scheme. This allows composition
@@ -0,0 +1,41 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and dns-masquerading-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1beta1 "github.com/sap/dns-masquerading-operator/api/v1beta1"
+	dnscssapcomv1beta1 "github.com/sap/dns-masquerading-operator/pkg/client/clientset/versioned/typed/dns.cs.sap.com/v1beta1"
+	gentype "k8s.io/client-go/gentype"
+)
+
+// fakeMasqueradingrules implements MasqueradingRuleInterface
+type fakeMasqueradingrules struct {
+	*gentype.FakeClientWithList[*v1beta1.MasqueradingRule, *v1beta1.MasqueradingRuleList]
+	Fake *FakeDnsV1beta1
+}
+
+func newFakeMasqueradingrules(fake *FakeDnsV1beta1, namespace string) dnscssapcomv1beta1.MasqueradingRuleInterface {
+	return &fakeMasqueradingrules{
+		gentype.NewFakeClientWithList[*v1beta1.MasqueradingRule, *v1beta1.MasqueradingRuleList](
+			fake.Fake,
+			namespace,
+			v1beta1.SchemeGroupVersion.WithResource("masqueradingrules"),
+			v1beta1.SchemeGroupVersion.WithKind("MasqueradingRule"),
+			func() *v1beta1.MasqueradingRule { return &v1beta1.MasqueradingRule{} },
+			func() *v1beta1.MasqueradingRuleList { return &v1beta1.MasqueradingRuleList{} },
+			func(dst, src *v1beta1.MasqueradingRuleList) { dst.ListMeta = src.ListMeta },
+			func(list *v1beta1.MasqueradingRuleList) []*v1beta1.MasqueradingRule {
+				return gentype.ToPointerSlice(list.Items)
+			},
+			func(list *v1beta1.MasqueradingRuleList, items []*v1beta1.MasqueradingRule) {
+				list.Items = gentype.FromPointerSlice(items)
+			},
+		),
+		fake,
+	}
+}
@@ -11,6 +11,7 @@ import (
 	fmt "fmt"
 
 	v1alpha1 "github.com/sap/dns-masquerading-operator/api/v1alpha1"
+	v1beta1 "github.com/sap/dns-masquerading-operator/api/v1beta1"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	cache "k8s.io/client-go/tools/cache"
 )
@@ -45,6 +46,10 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	case v1alpha1.SchemeGroupVersion.WithResource("masqueradingrules"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Dns().V1alpha1().Masqueradingrules().Informer()}, nil
 
+		// Group=dns.cs.sap.com, Version=v1beta1
+	case v1beta1.SchemeGroupVersion.WithResource("masqueradingrules"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Dns().V1beta1().Masqueradingrules().Informer()}, nil
+
 	}
 
 	return nil, fmt.Errorf("no informer found for %v", resource)